@@ -0,0 +1,126 @@
+package frcntgo
+
+import (
+	"net"
+	"time"
+
+	"github.com/techplexengineer/frc-networktables-go/message"
+)
+
+// outgoingBatch collects the messages written to a connection together. An
+// update to an entry that already has an update waiting replaces it, so only
+// the latest value is sent.
+type outgoingBatch struct {
+	conn        net.Conn
+	protocolRev [2]byte
+	encoder     *message.Encoder
+	messages    []message.IMessage
+	// updates holds the position of the waiting update for each entry ID. It
+	// is reset by any other message, so updates never move past them.
+	updates map[[2]byte]int
+	// failed is set once a write to the connection failed, after which
+	// nothing more is written to it
+	failed bool
+}
+
+func newOutgoingBatch() *outgoingBatch {
+	return &outgoingBatch{
+		encoder: message.NewEncoder(nil),
+		updates: map[[2]byte]int{},
+	}
+}
+
+// reset points the batch at a new connection
+func (batch *outgoingBatch) reset(conn net.Conn, protocolRev [2]byte) {
+	batch.conn, batch.protocolRev = conn, protocolRev
+	batch.failed = false
+}
+
+// add appends the message to the batch, merging it with a waiting update
+func (batch *outgoingBatch) add(msg message.IMessage) {
+	update, ok := msg.(*message.EntryUpdate)
+	if !ok {
+		batch.messages = append(batch.messages, msg)
+		batch.resetUpdates()
+		return
+	}
+	id := update.GetUpdate().GetRawID()
+	if index, waiting := batch.updates[id]; waiting {
+		batch.messages[index] = msg
+		return
+	}
+	batch.updates[id] = len(batch.messages)
+	batch.messages = append(batch.messages, msg)
+}
+
+// empty returns whether there is nothing waiting to be written
+func (batch *outgoingBatch) empty() bool {
+	return len(batch.messages) == 0
+}
+
+// resetUpdates forgets the waiting updates, keeping the map for reuse
+func (batch *outgoingBatch) resetUpdates() {
+	for id := range batch.updates {
+		delete(batch.updates, id)
+	}
+}
+
+// write encodes the messages of the batch for the protocol revision of its
+// connection, writes them to it in a single write and empties the batch.
+// Messages that can not be encoded are logged and left out, and nothing is
+// written once a write has failed. It returns whether anything was written.
+func (batch *outgoingBatch) write(logger Logger, writeTimeout time.Duration) (bool, error) {
+	if batch.failed {
+		batch.discard()
+		return false, nil
+	}
+	batch.encoder.Reset(batch.conn)
+	for index, msg := range batch.messages {
+		batch.messages[index] = nil
+		if batch.protocolRev != protocolRev2 {
+			batch.encoder.Encode(msg)
+			continue
+		}
+		if err := batch.encoder.EncodeRev2(msg); err != nil {
+			logger.Errorf("client: %s", err)
+		}
+	}
+	batch.discard()
+	if batch.encoder.Buffered() == 0 {
+		return false, nil
+	}
+	// a server that stopped reading must not block the client forever
+	batch.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := batch.encoder.Flush(); err != nil {
+		batch.failed = true
+		return false, err
+	}
+	return true, nil
+}
+
+// discard empties the batch
+func (batch *outgoingBatch) discard() {
+	for index := range batch.messages {
+		batch.messages[index] = nil
+	}
+	batch.messages = batch.messages[:0]
+	batch.resetUpdates()
+}
+
+// release returns the batch's buffer to the pool once the writer is done with it
+func (batch *outgoingBatch) release() {
+	batch.encoder.Release()
+}
+
+// batched returns whether the message may wait for the batch it is in to be
+// flushed. Changes to entries may, while the handshake, keep alives and
+// remote procedure calls are sent right away.
+func batched(msg message.IMessage) bool {
+	switch msg.GetType() {
+	case message.TypeEntryAssign, message.TypeEntryUpdate, message.TypeEntryFlagUpdate,
+		message.TypeEntryDelete, message.TypeClearAllEntries:
+		return true
+	default:
+		return false
+	}
+}
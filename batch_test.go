@@ -0,0 +1,99 @@
+package frcntgo
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/entryupdate"
+	"github.com/techplexengineer/frc-networktables-go/message"
+)
+
+// doubleUpdate returns an update of the entry with the ID to the value
+func doubleUpdate(id byte, value float64) message.IMessage {
+	return message.EntryUpdateFromUpdate(entryupdate.DoubleFromValue([2]byte{0x00, id}, [2]byte{0x00, byte(value)}, value))
+}
+
+func TestOutgoingBatchMergesUpdates(t *testing.T) {
+	conn, server := net.Pipe()
+	defer conn.Close()
+	defer server.Close()
+	batch := newOutgoingBatch()
+	defer batch.release()
+	batch.reset(conn, protocolRev3)
+
+	batch.add(doubleUpdate(1, 1))
+	batch.add(doubleUpdate(2, 1))
+	batch.add(doubleUpdate(1, 2))
+	// updates never move past other messages
+	batch.add(message.EntryDeleteFromItems([2]byte{0x00, 0x03}))
+	batch.add(doubleUpdate(1, 3))
+	batch.add(doubleUpdate(1, 4))
+	want := []message.IMessage{
+		doubleUpdate(1, 2),
+		doubleUpdate(2, 1),
+		message.EntryDeleteFromItems([2]byte{0x00, 0x03}),
+		doubleUpdate(1, 4),
+	}
+
+	written := make(chan []byte, 1)
+	go func() {
+		data, _ := io.ReadAll(server)
+		written <- data
+	}()
+	if ok, err := batch.write(quietLogger, time.Second); !ok || err != nil {
+		t.Fatalf("write returned %v, %v", ok, err)
+	}
+	if !batch.empty() {
+		t.Fatal("the batch was not emptied by the write")
+	}
+	conn.Close()
+	var wantData []byte
+	for _, msg := range want {
+		wantData = append(wantData, msg.CompressToBytes()...)
+	}
+	if data := <-written; !bytes.Equal(data, wantData) {
+		t.Fatalf("wrote %x, want %x", data, wantData)
+	}
+
+	// once a write failed nothing more is written
+	batch.add(doubleUpdate(1, 5))
+	if _, err := batch.write(quietLogger, time.Second); err == nil {
+		t.Fatal("writing to a closed connection succeeded")
+	}
+	batch.add(doubleUpdate(1, 6))
+	if ok, err := batch.write(quietLogger, time.Second); ok || err != nil {
+		t.Fatalf("write after a failure returned %v, %v", ok, err)
+	}
+	if !batch.empty() {
+		t.Fatal("the batch was not emptied after a failure")
+	}
+}
+
+func TestClientFlush(t *testing.T) {
+	server, port := startServer(t)
+	client := startClient(t, port, WithFlushPeriod(time.Hour))
+	if err := client.PutDouble("/speed", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	// the change is held back for the flush period
+	time.Sleep(100 * time.Millisecond)
+	if server.GetEntry("/speed") != nil {
+		t.Fatal("the change was written before the flush period ended")
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		e := server.GetEntry("/speed")
+		return e != nil && e.GetType() == entry.TypeDouble && e.GetValue() == 1.5
+	}, "the flushed change never reached the server")
+
+	client.Close()
+	if err := client.Flush(); err == nil {
+		t.Fatal("Flush of a closed client succeeded")
+	}
+}
@@ -0,0 +1,186 @@
+package frcntgo
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+// bindTag is the struct tag naming the entry a field is bound to. Fields
+// without it use their own name, and fields tagged "-" are left alone.
+const bindTag = "nt"
+
+// Binding keeps the fields of a struct in step with the entries of a table.
+// The struct is written from the notification goroutine, so it must only be
+// read while holding the binding's lock.
+type Binding struct {
+	sync.Mutex
+	table    *Table
+	target   reflect.Value
+	fields   map[string]boundField
+	listener *Listener
+}
+
+// boundField is a field of the struct and the type of the entry it is bound to
+type boundField struct {
+	index     []int
+	entryType entry.EntryType
+}
+
+// Bind fills the struct pointed to by target from the entries of the table and
+// keeps it updated as the entries change. Fields are bound to the entry named
+// by their nt tag, nested structs to sub tables and slices to array entries.
+// Bool, string and []byte fields are bound to boolean, string and raw entries,
+// and every numeric field to a double entry.
+func Bind(table *Table, target interface{}) (*Binding, error) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bind: target must be a pointer to a struct, not a %T", target)
+	}
+	binding := &Binding{
+		table:  table,
+		target: value.Elem(),
+		fields: map[string]boundField{},
+	}
+	if err := binding.addFields(value.Elem().Type(), "", nil); err != nil {
+		return nil, err
+	}
+	// listening before reading the current values means no change is missed
+	binding.listener = table.AddSubTableListener(EventCreated|EventUpdated|EventLocal, binding.entryChanged)
+	binding.Lock()
+	defer binding.Unlock()
+	for key, field := range binding.fields {
+		if value, err := table.client.getValue(table.key(key), field.entryType); err == nil {
+			binding.set(field, value)
+		}
+	}
+	return binding, nil
+}
+
+// addFields binds the fields of the struct type to the entries below the prefix
+func (binding *Binding) addFields(structType reflect.Type, prefix string, index []int) error {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			// unexported fields can not be set
+			continue
+		}
+		name, ok := field.Tag.Lookup(bindTag)
+		if name == "-" {
+			continue
+		}
+		if !ok || name == "" {
+			name = field.Name
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := binding.addFields(field.Type, prefix+name+pathSeparator, fieldIndex); err != nil {
+				return err
+			}
+			continue
+		}
+		entryType, ok := bindType(field.Type)
+		if !ok {
+			return fmt.Errorf("bind: field %s has unsupported type %s", field.Name, field.Type)
+		}
+		binding.fields[prefix+name] = boundField{index: fieldIndex, entryType: entryType}
+	}
+	return nil
+}
+
+// bindType returns the type of entry a field of the type is bound to
+func bindType(fieldType reflect.Type) (entry.EntryType, bool) {
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return entry.TypeBoolean, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return entry.TypeDouble, true
+	case reflect.String:
+		return entry.TypeString, true
+	case reflect.Slice:
+		for entryType, sliceType := range bindSliceTypes {
+			if fieldType.Elem() == sliceType.Elem() {
+				return entryType, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// bindSliceTypes holds the type of the value of each entry type bound to slices
+var bindSliceTypes = map[entry.EntryType]reflect.Type{
+	entry.TypeRaw:        reflect.TypeOf([]byte{}),
+	entry.TypeBooleanArr: reflect.TypeOf([]bool{}),
+	entry.TypeDoubleArr:  reflect.TypeOf([]float64{}),
+	entry.TypeStringArr:  reflect.TypeOf([]string{}),
+}
+
+// entryChanged copies a new value of a bound entry into its field
+func (binding *Binding) entryChanged(notification EntryNotification) {
+	field, ok := binding.fields[notification.Key]
+	if !ok || field.entryType != notification.Type {
+		return
+	}
+	binding.Lock()
+	defer binding.Unlock()
+	binding.set(field, notification.Value)
+}
+
+// set stores the value of an entry in its field. The caller must hold the lock.
+func (binding *Binding) set(field boundField, value interface{}) {
+	target := binding.target.FieldByIndex(field.index)
+	source := reflect.ValueOf(value)
+	if source.Kind() == reflect.Slice {
+		// the entry's value is shared, so the field gets a copy
+		copied := reflect.MakeSlice(target.Type(), source.Len(), source.Len())
+		reflect.Copy(copied, source)
+		target.Set(copied)
+		return
+	}
+	target.Set(source.Convert(target.Type()))
+}
+
+// Publish writes the current value of every field to its entry, creating the
+// entries that do not exist yet
+func (binding *Binding) Publish() error {
+	binding.Lock()
+	values := map[string]interface{}{}
+	for key, field := range binding.fields {
+		values[key] = binding.get(field)
+	}
+	binding.Unlock()
+	for key, value := range values {
+		err := binding.table.client.setValue(binding.table.key(key), binding.fields[key].entryType, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get returns the value of a field as the value of its entry. The caller must hold the lock.
+func (binding *Binding) get(field boundField) interface{} {
+	source := binding.target.FieldByIndex(field.index)
+	switch field.entryType {
+	case entry.TypeDouble:
+		return source.Convert(reflect.TypeOf(float64(0))).Interface()
+	case entry.TypeRaw, entry.TypeBooleanArr, entry.TypeDoubleArr, entry.TypeStringArr:
+		// the entry must not share the field's backing array
+		copied := reflect.MakeSlice(bindSliceTypes[field.entryType], source.Len(), source.Len())
+		reflect.Copy(copied, source)
+		return copied.Interface()
+	case entry.TypeBoolean:
+		return source.Bool()
+	default:
+		return source.String()
+	}
+}
+
+// Unbind stops updating the struct
+func (binding *Binding) Unbind() {
+	binding.listener.Unregister()
+}
@@ -0,0 +1,220 @@
+package frcntgo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+// boundDrive has a field of every kind Bind supports
+type boundDrive struct {
+	Speed   float64 `nt:"speed"`
+	Count   int
+	Enabled bool
+	Mode    string `nt:"mode"`
+	Data    []byte
+	Names   []string
+	Ratios  []float64
+	Flags   []bool
+	Ignored float64 `nt:"-"`
+	hidden  float64
+	Arm     struct {
+		Angle float64 `nt:"angle"`
+		Wrist struct {
+			Open bool `nt:"open"`
+		} `nt:"wrist"`
+	} `nt:"arm"`
+}
+
+// readBound returns a copy of the bound struct, taken under the binding's lock
+func readBound(binding *Binding, drive *boundDrive) boundDrive {
+	binding.Lock()
+	defer binding.Unlock()
+	return *drive
+}
+
+func TestBindReadsEntries(t *testing.T) {
+	server, port := startServer(t)
+	values := map[string]struct {
+		entryType entry.EntryType
+		value     interface{}
+	}{
+		"/drive/speed":           {entry.TypeDouble, 1.5},
+		"/drive/Count":           {entry.TypeDouble, 3.9},
+		"/drive/Enabled":         {entry.TypeBoolean, true},
+		"/drive/mode":            {entry.TypeString, "tank"},
+		"/drive/Data":            {entry.TypeRaw, []byte{0x01, 0x02}},
+		"/drive/Names":           {entry.TypeStringArr, []string{"left", "right"}},
+		"/drive/Ratios":          {entry.TypeDoubleArr, []float64{0.5, 2}},
+		"/drive/Flags":           {entry.TypeBooleanArr, []bool{false, true}},
+		"/drive/Ignored":         {entry.TypeDouble, 7.0},
+		"/drive/hidden":          {entry.TypeDouble, 7.0},
+		"/drive/arm/angle":       {entry.TypeDouble, 90.0},
+		"/drive/arm/wrist/open":  {entry.TypeBoolean, true},
+		"/drive/arm/wrist/Open":  {entry.TypeBoolean, false},
+		"/drive/unbound":         {entry.TypeDouble, 7.0},
+		"/drive/arm/wrist/other": {entry.TypeString, "x"},
+	}
+	for key, value := range values {
+		if err := server.SetValue(key, value.entryType, value.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := startClient(t, port)
+
+	var drive boundDrive
+	binding, err := Bind(client.GetTable("/drive"), &drive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer binding.Unbind()
+	got := readBound(binding, &drive)
+	var want boundDrive
+	want.Speed = 1.5
+	// doubles are converted to the type of the field
+	want.Count = 3
+	want.Enabled = true
+	want.Mode = "tank"
+	want.Data = []byte{0x01, 0x02}
+	want.Names = []string{"left", "right"}
+	want.Ratios = []float64{0.5, 2}
+	want.Flags = []bool{false, true}
+	want.Arm.Angle = 90
+	want.Arm.Wrist.Open = true
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bound %+v, want %+v", got, want)
+	}
+
+	// the fields hold copies of the entries' values
+	got.Ratios[0] = 100
+	if value, _ := client.GetDoubleArray("/drive/Ratios"); value[0] != 0.5 {
+		t.Fatal("the field shares its backing array with the entry")
+	}
+}
+
+func TestBindFollowsUpdates(t *testing.T) {
+	server, port := startServer(t)
+	client := startClient(t, port)
+	var drive boundDrive
+	binding, err := Bind(client.GetTable("/drive"), &drive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// entries created after Bind, by the server and by the client itself
+	server.SetValue("/drive/speed", entry.TypeDouble, 2.0)
+	server.SetValue("/drive/arm/angle", entry.TypeDouble, 45.0)
+	client.PutStringArray("/drive/Names", []string{"front"})
+	client.PutDouble("/drive/Count", 4.2)
+	eventually(t, func() bool {
+		got := readBound(binding, &drive)
+		return got.Speed == 2 && got.Arm.Angle == 45 && reflect.DeepEqual(got.Names, []string{"front"}) && got.Count == 4
+	}, "the binding never followed the new entries")
+
+	// later updates to them
+	server.SetValue("/drive/speed", entry.TypeDouble, 3.0)
+	client.PutStringArray("/drive/Names", []string{"back"})
+	eventually(t, func() bool {
+		got := readBound(binding, &drive)
+		return got.Speed == 3 && reflect.DeepEqual(got.Names, []string{"back"})
+	}, "the binding never followed the updates")
+
+	// an entry of another type than the field is ignored
+	server.SetValue("/drive/mode", entry.TypeDouble, 1.0)
+	server.SetValue("/drive/Ignored", entry.TypeDouble, 1.0)
+
+	binding.Unbind()
+	seen := make(chan struct{})
+	listener := client.AddKeyListener("/drive/speed", EventUpdated, func(EntryNotification) { close(seen) })
+	defer listener.Unregister()
+	server.SetValue("/drive/speed", entry.TypeDouble, 4.0)
+	<-seen
+	if got := readBound(binding, &drive); got.Speed != 3 || got.Mode != "" || got.Ignored != 0 {
+		t.Fatalf("the binding changed to %+v after Unbind", got)
+	}
+}
+
+func TestBindPublish(t *testing.T) {
+	_, port := startServer(t)
+	client := startClient(t, port)
+	var drive boundDrive
+	binding, err := Bind(client.GetTable("/drive"), &drive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer binding.Unbind()
+
+	binding.Lock()
+	drive.Speed = 1.25
+	drive.Count = 7
+	drive.Mode = "arcade"
+	drive.Data = []byte{0xff}
+	drive.Flags = []bool{true}
+	drive.Ignored = 9
+	drive.Arm.Wrist.Open = true
+	binding.Unlock()
+	if err := binding.Publish(); err != nil {
+		t.Fatal(err)
+	}
+
+	table := client.GetTable("/drive")
+	if value, _ := table.GetDouble("speed"); value != 1.25 {
+		t.Errorf("speed is %v, want 1.25", value)
+	}
+	if value, _ := table.GetDouble("Count"); value != 7 {
+		t.Errorf("Count is %v, want 7", value)
+	}
+	if value, _ := table.GetString("mode"); value != "arcade" {
+		t.Errorf("mode is %q, want arcade", value)
+	}
+	if value, _ := table.GetRaw("Data"); !reflect.DeepEqual(value, []byte{0xff}) {
+		t.Errorf("Data is %v, want [255]", value)
+	}
+	if value, _ := table.GetBooleanArray("Flags"); !reflect.DeepEqual(value, []bool{true}) {
+		t.Errorf("Flags is %v, want [true]", value)
+	}
+	if value, _ := table.GetSubTable("arm/wrist").GetBoolean("open"); !value {
+		t.Error("arm/wrist/open was not published")
+	}
+	// nil slices are published as empty arrays
+	if value, err := table.GetStringArray("Names"); err != nil || len(value) != 0 {
+		t.Errorf("Names is %v, %v, want an empty array", value, err)
+	}
+	if table.ContainsKey("Ignored") || table.ContainsKey("hidden") {
+		t.Error("fields that are not bound were published")
+	}
+
+	// the entry does not share the field's backing array
+	binding.Lock()
+	drive.Data[0] = 0x00
+	binding.Unlock()
+	if value, _ := table.GetRaw("Data"); value[0] != 0xff {
+		t.Error("the entry shares its backing array with the field")
+	}
+}
+
+func TestBindRejectsUnsupportedTypes(t *testing.T) {
+	_, port := startServer(t)
+	table := startClient(t, port).GetTable("/drive")
+	targets := []interface{}{
+		boundDrive{},
+		new(float64),
+		&struct{ Motors map[string]float64 }{},
+		&struct{ Counts []int }{},
+		&struct{ Speed *float64 }{},
+		&struct{ Nested struct{ Done chan bool } }{},
+	}
+	for _, target := range targets {
+		if _, err := Bind(table, target); err == nil {
+			t.Errorf("bound a %T", target)
+		}
+	}
+	// unsupported fields are fine when they are skipped
+	if _, err := Bind(table, &struct {
+		Motors map[string]float64 `nt:"-"`
+		done   chan bool
+	}{}); err != nil {
+		t.Error(err)
+	}
+}
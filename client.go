@@ -1,16 +1,22 @@
 package frcntgo
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/techplexengineer/frc-networktables-go/entryupdate"
 	"io"
-	"log"
 	"net"
+	"os"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/techplexengineer/frc-networktables-go/codec"
 	"github.com/techplexengineer/frc-networktables-go/entry"
 	"github.com/techplexengineer/frc-networktables-go/message"
 	"github.com/techplexengineer/frc-networktables-go/util"
@@ -37,27 +43,118 @@ const (
 	// ClientInSync indicates that the client is completely in sync
 	// with the server and has all the correct values.
 	ClientInSync
-	// keepAliveTime is the amount of time (seconds) between packets
-	// that the client waits before it sends a KeepAlive message.
-	// It is advised to never have this lower than one second so as to
-	// prevent overloading the server.
-	keepAliveTime int64 = 1
 )
 
-var (
-	lastSent            int64
-	messageOutgoingChan = make(chan message.IMessage)
+const (
+	// keepAliveInterval is the amount of time between packets that the
+	// client waits before it sends a KeepAlive message.
+	// It is advised to never have this lower than one second so as to
+	// prevent overloading the server.
+	keepAliveInterval = time.Second
+	// keepAliveTimeout is the amount of time the client waits to hear from
+	// the server before it considers the connection dead
+	keepAliveTimeout = 5 * time.Second
+	// dialTimeout is the amount of time the client waits for the server to
+	// accept a connection
+	dialTimeout = 5 * time.Second
+	// flushPeriod is the amount of time changes to entries are held back so
+	// they can be written together, as recommended by the specification
+	flushPeriod = 100 * time.Millisecond
 )
 
-// Client is the NetworkTables Client
+// Client is the NetworkTables Client. It is safe for concurrent use.
 type Client struct {
+	// mutex guards the connection state below and serialises every change
+	// to the entries. Reading entries does not need it.
+	mutex sync.Mutex
 	//handler ClientMessageHandler
-	conn    net.Conn
-	entries map[string]entry.IEntry
-	status  ClientStatus
+	address string
+	// identity is sent to the server, which uses it to tell a reconnecting
+	// client from a new one
+	identity string
+	// ctx is the context the client was created with. Every dial uses it,
+	// and the client is closed when it ends.
+	ctx         context.Context
+	dialTimeout time.Duration
+	logger      Logger
+	// connMutex guards conn and protocolRev for the writing goroutine, which
+	// must not wait on mutex while messages are being queued
+	connMutex sync.Mutex
+	conn      net.Conn
+	// protocolRev is the protocol revision used with the server, lowered
+	// when the server does not support NetworkTables 3.0
+	protocolRev [2]byte
+	entries     *entryStore
+	status      ClientStatus
+	// statusChanged is closed and replaced whenever the status changes
+	statusChanged chan struct{}
+	// pending holds the names of entries created locally that the server
+	// has not assigned an ID to yet, and whether their EntryAssign was sent
+	pending map[string]bool
+	// owned holds the names of entries this client has published or changed,
+	// and dirty those changed while the client was not in sync with the server
+	owned map[string]bool
+	dirty map[string]bool
+
+	// reconnect enables redialing the server when the connection is lost,
+	// waiting between reconnectMin and reconnectMax between attempts
+	reconnect    bool
+	reconnectMin time.Duration
+	reconnectMax time.Duration
+	// synced is set once the first handshake has completed, and
+	// serverRestarted when a later handshake finds the server has forgotten us
+	synced          bool
+	serverRestarted bool
+	// previous holds the entries known before the handshake in progress and
+	// assigned the names the server has assigned during it
+	previous map[string]entry.IEntry
+	assigned map[string]bool
+	closed   bool
+	// done is closed once the client is closed, through stopOnce as it is
+	// closed before the mutex is taken
+	done     chan struct{}
+	stopOnce sync.Once
+	// outgoing holds the messages waiting to be written to the server, and
+	// flushes the requests to write the changes held back for flushPeriod
+	outgoing    chan message.IMessage
+	flushes     chan chan struct{}
+	flushPeriod time.Duration
+	// keepAliveInterval is how long the connection may be idle before a
+	// KeepAlive is sent, and keepAliveTimeout how long the server may be
+	// silent before the connection is considered lost
+	keepAliveInterval time.Duration
+	keepAliveTimeout  time.Duration
+	// maxValueLength is the number of bytes a string or raw value received
+	// from the server may hold
+	maxValueLength int
+
+	// entryListeners and connectionListeners hold the registered listeners by
+	// their ID, and notifications the calls to them waiting to be made
+	listenerMutex       sync.Mutex
+	nextListener        int
+	entryListeners      map[int]*entryListener
+	connectionListeners map[int]ConnectionListener
+	notifyMutex         sync.Mutex
+	notifications       []func()
+	notifyReady         chan struct{}
+
+	// rpcCalls holds the outstanding remote procedure calls waiting on a response
+	// and rpcHandlers the procedures this client serves, by entry name
+	rpcMutex    sync.Mutex
+	rpcCalls    map[rpcCall]chan *message.RPCResponse
+	rpcUniqueID uint16
+	rpcHandlers map[string]RPCHandler
+}
+
+// rpcCall identifies a single call of a remote procedure
+type rpcCall struct {
+	id       [2]byte
+	uniqueID [2]byte
 }
 
 func (c *Client) GetStatus() ClientStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	return c.status
 }
 
@@ -75,210 +172,1308 @@ func NewClientTeam(teamNumber int) (*Client, error) {
 	return NewClient(address, port)
 }
 
+// ClientOption configures optional behaviour of a Client
+type ClientOption func(*Client)
+
+// WithReconnect makes the client redial the server whenever the connection is
+// lost, or cannot be made at all. The delay between attempts starts at
+// minDelay and doubles after each failure up to maxDelay. The minimum delay
+// must be positive, and the maximum at least as long.
+func WithReconnect(minDelay, maxDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.reconnect = true
+		c.reconnectMin = minDelay
+		c.reconnectMax = maxDelay
+	}
+}
+
+// WithKeepAlive changes how long the connection may be idle before a KeepAlive
+// is sent, and how long the server may be silent before the connection is
+// considered lost. A timeout of zero waits on the server forever. Any other
+// timeout must be longer than the interval, and than the interval the server
+// sends its own KeepAlives at, which is one second for a Server by default.
+func WithKeepAlive(interval, timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.keepAliveInterval = interval
+		c.keepAliveTimeout = timeout
+	}
+}
+
+// WithDialTimeout changes how long the client waits for the server to accept
+// a connection
+func WithDialTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.dialTimeout = timeout
+	}
+}
+
+// WithFlushPeriod changes how long changes to entries are held back so they
+// can be written to the server together. A period of zero writes every
+// message right away.
+func WithFlushPeriod(period time.Duration) ClientOption {
+	return func(c *Client) {
+		c.flushPeriod = period
+	}
+}
+
+// WithMaxValueLength changes the number of bytes a string or raw value
+// received from the server may hold. A longer value is treated as a broken
+// connection.
+func WithMaxValueLength(maxLength int) ClientOption {
+	return func(c *Client) {
+		c.maxValueLength = maxLength
+	}
+}
+
+// WithIdentity changes the identity the client sends to the server. The
+// server uses it to tell whether the client has connected before, so every
+// client must have its own. By default it is made from the host name, the
+// process ID and a counter.
+func WithIdentity(identity string) ClientOption {
+	return func(c *Client) {
+		c.identity = identity
+	}
+}
+
+// clientCount numbers the clients made by this process for their default identity
+var clientCount uint32
+
+// defaultIdentity returns an identity no other client shares
+func defaultIdentity() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("frc-nt-golang@%s:%d:%d", host, os.Getpid(), atomic.AddUint32(&clientCount, 1))
+}
+
+// WithLogger sends the client's log messages to the logger
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
 // Create a new Network Tables client
 // connAddr can be an IP or hostname
 // connPort is the tcp port to connect to. Usually Network Tables uses port 1735
-func NewClient(connAddr, connPort string) (*Client, error) {
-	tcpConn, err := net.Dial("tcp", util.ConcatAddress(connAddr, connPort))
-	if err != nil {
-		return &Client{
-			conn:    nil,
-			entries: map[string]entry.IEntry{},
-			status:  ClientDisconnected,
-		}, err
+func NewClient(connAddr, connPort string, options ...ClientOption) (*Client, error) {
+	return NewClientContext(context.Background(), connAddr, connPort, options...)
+}
+
+// NewClientContext creates a new Network Tables client like NewClient. The
+// context bounds the dial to the server, and when it ends the client is
+// closed and every goroutine it started stops.
+func NewClientContext(ctx context.Context, connAddr, connPort string, options ...ClientOption) (*Client, error) {
+	client := &Client{
+		address:        util.ConcatAddress(connAddr, connPort),
+		identity:       defaultIdentity(),
+		ctx:            ctx,
+		dialTimeout:    dialTimeout,
+		logger:         defaultLogger(),
+		conn:           nil,
+		protocolRev:    protocolRev3,
+		entries:        newEntryStore(),
+		status:         ClientDisconnected,
+		statusChanged:  make(chan struct{}),
+		pending:        map[string]bool{},
+		owned:          map[string]bool{},
+		dirty:          map[string]bool{},
+		done:           make(chan struct{}),
+		outgoing:       make(chan message.IMessage),
+		flushes:        make(chan chan struct{}),
+		flushPeriod:    flushPeriod,
+		maxValueLength: codec.DefaultMaxLength,
+		rpcCalls:       map[rpcCall]chan *message.RPCResponse{},
+		rpcHandlers:    map[string]RPCHandler{},
+
+		keepAliveInterval: keepAliveInterval,
+		keepAliveTimeout:  keepAliveTimeout,
+
+		entryListeners:      map[int]*entryListener{},
+		connectionListeners: map[int]ConnectionListener{},
+		notifyReady:         make(chan struct{}, 1),
 	}
-	client := Client{
-		conn:    tcpConn,
-		entries: map[string]entry.IEntry{},
-		status:  ClientConnected,
+	for _, option := range options {
+		option(client)
 	}
-	defer client.connect()
-	return &client, nil
+	if client.keepAliveInterval <= 0 {
+		return nil, fmt.Errorf("client: keep alive interval %s is not positive", client.keepAliveInterval)
+	}
+	if client.keepAliveTimeout > 0 && client.keepAliveTimeout <= client.keepAliveInterval {
+		return nil, fmt.Errorf("client: keep alive timeout %s is not longer than the interval %s",
+			client.keepAliveTimeout, client.keepAliveInterval)
+	}
+	if client.reconnect && client.reconnectMin <= 0 {
+		return nil, fmt.Errorf("client: reconnect delay %s is not positive", client.reconnectMin)
+	}
+	if client.reconnect && client.reconnectMax < client.reconnectMin {
+		return nil, fmt.Errorf("client: maximum reconnect delay %s is shorter than the minimum %s",
+			client.reconnectMax, client.reconnectMin)
+	}
+	tcpConn, err := client.dial()
+	if err != nil {
+		if !client.reconnect || ctx.Err() != nil {
+			return client, err
+		}
+		client.logger.Warnf("client: %s, retrying", err)
+		client.start()
+		go client.redial()
+		return client, nil
+	}
+	client.start()
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	client.connect(tcpConn)
+	return client, nil
 }
 
-func (c *Client) connect() {
+// start runs the goroutines that live as long as the client
+func (c *Client) start() {
 	go c.processOutgoingQueue()
-	go c.receiveIncoming()
+	go c.dispatchNotifications()
+	if c.ctx.Done() != nil {
+		go func() {
+			select {
+			case <-c.ctx.Done():
+				c.Close()
+			case <-c.done:
+			}
+		}()
+	}
+}
+
+// dial opens a new connection to the server
+func (c *Client) dial() (net.Conn, error) {
+	dialer := net.Dialer{Timeout: c.dialTimeout}
+	return dialer.DialContext(c.ctx, "tcp", c.address)
+}
+
+// connect starts using a new connection to the server. The caller must hold the mutex.
+func (c *Client) connect(conn net.Conn) {
+	c.connMutex.Lock()
+	c.conn = conn
+	c.connMutex.Unlock()
+	c.setStatus(ClientConnected)
+	go c.receiveIncoming(conn)
 	c.startHandshake()
 }
 
-func (c *Client) startHandshake() {
-	clientName := []byte("frc-nt-golang")
-	clientLength := util.EncodeULeb128(uint32(len(clientName)))
-	clientName = append(clientLength, clientName...)
-	VERSION := [2]byte{0x03, 0x00}
+// redial connects to the server again after the connection was lost,
+// backing off exponentially while the server cannot be reached
+func (c *Client) redial() {
+	delay := c.reconnectMin
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(delay):
+		}
+		conn, err := c.dial()
+		if err == nil {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			if c.closed {
+				conn.Close()
+				return
+			}
+			// the server may have been replaced by one speaking the newer
+			// revision, so every reconnect starts with it again
+			c.connMutex.Lock()
+			c.protocolRev = protocolRev3
+			c.connMutex.Unlock()
+			c.connect(conn)
+			return
+		}
+		c.logger.Warnf("client: reconnect failed: %s", err)
+		delay *= 2
+		if delay > c.reconnectMax {
+			delay = c.reconnectMax
+		}
+	}
+}
+
+// retryHandshake dials the server again after it rejected the protocol
+// revision used on the lost connection. The mutex is only taken once the
+// dial is over.
+func (c *Client) retryHandshake(lost net.Conn) {
+	conn, err := c.dial()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err != nil {
+		c.logger.Errorf("client: %s", err)
+		c.connectionLost(lost)
+		return
+	}
+	if c.closed || c.conn != lost {
+		conn.Close()
+		return
+	}
+	c.connect(conn)
+}
 
+// connectionLost tears down a connection that failed, and redials the
+// server if reconnecting is enabled. The caller must hold the mutex.
+func (c *Client) connectionLost(conn net.Conn) {
+	if c.closed || conn != c.conn {
+		return
+	}
+	if !c.reconnect {
+		c.close()
+		return
+	}
+	c.setStatus(ClientDisconnected)
+	conn.Close()
+	go c.redial()
+}
+
+func (c *Client) startHandshake() {
+	clientName := codec.EncodeString(c.identity)
 	// Step 1: Client sends Client Hello
-	helloMessage := message.ClientHelloFromItems(VERSION, clientName)
-	c.QueueMessage(helloMessage)
-	c.status = ClientSentHello
+	helloMessage, err := message.ClientHelloFromItems(c.protocolRev, clientName)
+	if err != nil {
+		c.logger.Errorf("client: %s", err)
+		return
+	}
+	c.queueMessage(helloMessage)
+	c.setStatus(ClientSentHello)
 }
 
 // Close disconnects and closes the client from the server.
 func (c *Client) Close() error {
-	if c.status == ClientDisconnected {
+	// a caller holding the mutex may be waiting on the writing goroutine,
+	// and gives up once done is closed
+	c.stop()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.close()
+}
+
+func (c *Client) close() error {
+	if c.closed {
 		return errors.New("client: Already disconnected")
 	}
-	c.status = ClientDisconnected
-	c.conn.Close()
+	c.closed = true
+	c.setStatus(ClientDisconnected)
+	c.stop()
+	if c.conn != nil {
+		c.conn.Close()
+	}
 	return nil
 }
 
+// WaitForConnected blocks until the client has connected to the server, the
+// client is closed or the context ends
+func (c *Client) WaitForConnected(ctx context.Context) error {
+	return c.waitForStatus(ctx, func(status ClientStatus) bool {
+		return status != ClientDisconnected
+	})
+}
+
+// WaitForSync blocks until the client is in sync with the server, the
+// client is closed or the context ends
+func (c *Client) WaitForSync(ctx context.Context) error {
+	return c.waitForStatus(ctx, func(status ClientStatus) bool {
+		return status == ClientInSync
+	})
+}
+
+// waitForStatus blocks until the status of the client is one that is wanted
+func (c *Client) waitForStatus(ctx context.Context, wanted func(status ClientStatus) bool) error {
+	for {
+		c.mutex.Lock()
+		status, changed, closed := c.status, c.statusChanged, c.closed
+		c.mutex.Unlock()
+		if wanted(status) {
+			return nil
+		}
+		if closed {
+			return errors.New("client: closed")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		case <-c.done:
+		}
+	}
+}
+
+// stop closes done, which every goroutine of the client stops at
+func (c *Client) stop() {
+	c.stopOnce.Do(func() { close(c.done) })
+}
+
+// isClosed returns whether Close has been called, without taking the mutex
+func (c *Client) isClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
 // QueueMessage prepares the message that has been provided for
 // sending.
-func (c *Client) QueueMessage(message message.IMessage) error {
+func (c *Client) QueueMessage(msg message.IMessage) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.queueMessage(msg)
+}
+
+// queueMessage is QueueMessage for callers already holding the mutex
+func (c *Client) queueMessage(msg message.IMessage) error {
+	if c.protocolRev == protocolRev2 && !message.SupportedRev2(msg) {
+		return fmt.Errorf("client: %s is not supported by NetworkTables 2.0", msg.GetType())
+	}
 	if c.status != ClientDisconnected {
-		fmt.Printf("<=== Sending msg type %#x - %s\n", message.GetType().Byte(), message.GetType().String())
-		messageOutgoingChan <- message
-		return nil
+		c.logger.Debugf("client: <=== sending %s (%#x)", msg.GetType(), msg.GetType().Byte())
+		select {
+		case c.outgoing <- msg:
+			return nil
+		case <-c.done:
+			return errors.New("client: closed")
+		}
 	}
 	return errors.New("client: server could not be reached")
 }
 
-// process the queue of outgoing messages, should be called as a gofun
+// process the queue of outgoing messages, should be called as a gofun.
+// Changes to entries are held back for the flush period and written together,
+// while anything else flushes them right away. A KeepAlive is sent whenever
+// nothing was written for the keep alive interval.
 func (c *Client) processOutgoingQueue() {
-	for c.status != ClientDisconnected {
-		sending := <-messageOutgoingChan
-		c.conn.Write(sending.CompressToBytes())
-		defer updateLastSent() //@todo why is this deferred?
+	idle := time.NewTimer(c.keepAliveInterval)
+	defer idle.Stop()
+	// written is the last connection written to. KeepAlives are only sent
+	// on it, so they never go out before the ClientHello of a new connection.
+	var written net.Conn
+	batch := newOutgoingBatch()
+	defer batch.release()
+	// flushTimer fires once the oldest message in the batch has waited for the flush period
+	var flushTimer <-chan time.Time
+	for {
+		var sending message.IMessage
+		var flushed chan struct{}
+		select {
+		case <-c.done:
+			return
+		case sending = <-c.outgoing:
+		case flushed = <-c.flushes:
+		case <-flushTimer:
+		case <-idle.C:
+			sending = message.KeepAliveFromItems()
+		}
+		if sending != nil {
+			c.connMutex.Lock()
+			conn, protocolRev := c.conn, c.protocolRev
+			c.connMutex.Unlock()
+			if conn != batch.conn {
+				// what is left was meant for the previous connection
+				c.writeBatch(batch)
+				batch.reset(conn, protocolRev)
+			}
+			// nothing can be written while no connection has been made yet
+			if conn != nil && (sending.GetType() != message.TypeKeepAlive || conn == written) {
+				batch.add(sending)
+			}
+		}
+		if sending != nil && batched(sending) && c.flushPeriod > 0 {
+			if flushTimer == nil {
+				flushTimer = time.After(c.flushPeriod)
+			}
+			continue
+		}
+		flushTimer = nil
+		if c.writeBatch(batch) {
+			written = batch.conn
+		}
+		resetTimer(idle, c.keepAliveInterval)
+		if flushed != nil {
+			close(flushed)
+		}
+	}
+}
+
+// writeBatch writes the messages in the batch to its connection, and returns
+// whether anything was written. A connection that could not be written to is
+// torn down, as what was left of a partial write would garble the messages
+// after it.
+func (c *Client) writeBatch(batch *outgoingBatch) bool {
+	if batch.empty() {
+		return false
+	}
+	written, err := batch.write(c.logger, c.writeTimeout())
+	if err != nil {
+		c.logger.Warnf("client: write failed: %s", err)
+		// the mutex may be held by a caller waiting on this goroutine
+		go func(conn net.Conn) {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			c.connectionLost(conn)
+		}(batch.conn)
+	}
+	return written
+}
+
+// writeTimeout returns how long a write may take. Writes are bounded even
+// when the client waits on the server forever, as a server that stopped
+// reading would otherwise block the client.
+func (c *Client) writeTimeout() time.Duration {
+	if c.keepAliveTimeout > 0 {
+		return c.keepAliveTimeout
+	}
+	return keepAliveTimeout
+}
+
+// resetTimer restarts a timer that may already have fired
+func resetTimer(timer *time.Timer, duration time.Duration) {
+	if !timer.Stop() {
+		// drain a tick that raced with the reset
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(duration)
+}
+
+// Flush writes the changes to entries that are being held back for the flush
+// period to the server right away
+func (c *Client) Flush() error {
+	flushed := make(chan struct{})
+	select {
+	case c.flushes <- flushed:
+	case <-c.done:
+		return errors.New("client: closed")
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-c.done:
+		return errors.New("client: closed")
 	}
-	log.Printf("client: server could not be reached")
 }
 
 // readMessage
-func (c *Client) receiveIncoming() {
-	var potentialMessage [1]byte
-	for c.status != ClientDisconnected {
-		_, ioError := io.ReadFull(c.conn, potentialMessage[:])
-		if ioError != nil {
-			if ioError == io.EOF {
-				continue
+func (c *Client) receiveIncoming(conn net.Conn) {
+	decoder := message.NewDecoder(conn)
+	defer decoder.Release()
+	decoder.SetMaxLength(c.maxValueLength)
+	for !c.isClosed() {
+		if c.keepAliveTimeout > 0 {
+			// the server sends KeepAlives while idle, so silence means it is gone
+			conn.SetReadDeadline(time.Now().Add(c.keepAliveTimeout))
+		}
+		c.connMutex.Lock()
+		protocolRev := c.protocolRev
+		c.connMutex.Unlock()
+		var tempPacket message.IMessage
+		var err error
+		if protocolRev == protocolRev2 {
+			tempPacket, err = decoder.DecodeRev2(c.entryTypeByID)
+		} else {
+			tempPacket, err = decoder.Decode()
+		}
+		if err != nil {
+			// the server closing the connection is reported as io.EOF
+			var netError net.Error
+			switch {
+			case errors.As(err, &netError) && netError.Timeout():
+				c.logger.Warnf("client: server did not respond within %s", c.keepAliveTimeout)
+			case c.isClosed():
+			case errors.As(err, &netError) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+				c.logger.Warnf("client: io error: %s", err)
+			default:
+				c.logger.Errorf("client: message error: %s", err)
 			}
-			c.Close()
-			log.Printf("io error: %s", ioError)
+			c.mutex.Lock()
+			c.connectionLost(conn)
+			c.mutex.Unlock()
 			return //don't attempt to process any further
 		}
-		tempPacket, messageError := message.BuildFromReader(message.MessageType(potentialMessage[0]), c.conn)
-		if messageError != nil {
-			c.Close()
-			log.Printf("Message Error: %s", messageError)
-			return //don't attempt to process any further
+		c.logger.Debugf("client: ===> got %s", tempPacket.GetType())
+		c.mutex.Lock()
+		keepReading := c.handleMessage(conn, tempPacket)
+		c.mutex.Unlock()
+		if !keepReading {
+			return
 		}
-		fmt.Printf("===> got %s\n", tempPacket.GetType())
-		switch tempPacket.GetType() {
+	}
+}
 
-		case message.TypeServerHello:
-			// Step 2: Server replies to ClientHello with ServerHello
-			msg := tempPacket.(*message.ServerHello)
-			fmt.Printf("Connected to %s\n", msg.GetServerIdentity())
+// handleMessage applies a message received from the server, and returns
+// whether the connection should still be read from. The caller must hold the mutex.
+func (c *Client) handleMessage(conn net.Conn, tempPacket message.IMessage) bool {
+	switch tempPacket.GetType() {
 
-		case message.TypeEntryAssign:
-			// Step 3: Server sends EntryAssign messages for each entry
-			// if we just sent a client hello then the sync is beginning
-			if c.status == ClientSentHello {
-				c.status = ClientStartingSync
-			}
-			msg := tempPacket.(*message.EntryAssign)
-			c.entries[msg.GetEntry().GetName()] = msg.GetEntry()
-		case message.TypeServerHelloComplete:
-			// Step 4: The Server sends a Server Hello Complete message.
-			// Server is done sending entryAssigns
+	case message.TypeServerHello:
+		// Step 2: Server replies to ClientHello with ServerHello
+		msg := tempPacket.(*message.ServerHello)
+		c.logger.Infof("client: connected to %s", msg.GetServerIdentity())
+		c.startSync(msg.IsFirstConnection())
 
-			// Step 5: For all Entries the Client recognizes that the Server
-			// did not identify with a Entry Assignment.
-			// we can now send any entries the server should have @todo not implemented
+	case message.TypeEntryAssign:
+		// Step 3: Server sends EntryAssign messages for each entry
+		msg := tempPacket.(*message.EntryAssign)
+		assigned := msg.GetEntry()
+		name := assigned.GetName()
+		if c.status == ClientSentHello {
+			// NetworkTables 2.0 servers do not send a ServerHello, and
+			// can not tell whether they have seen this client before
+			c.startSync(true)
+		}
+		if c.status == ClientStartingSync {
+			// reconciled with the local entries once the server is done
+			delete(c.pending, name)
+			c.entries.put(assigned)
+			c.assigned[name] = true
+			break
+		}
+		// an assignment for one of our pending entries means the server
+		// has given it a real ID, so the local copy is replaced
+		local, hadLocal := c.entries.get(name)
+		_, isPending := c.pending[name]
+		delete(c.pending, name)
+		c.entries.put(assigned)
+		if hadLocal && isPending && local.GetType() == assigned.GetType() {
+			// the most recent local value and flags must be sent if they
+			// differ from the assigned ones
+			c.republish(local)
+		}
+		current, _ := c.entries.get(name)
+		c.notifyEntry(current, entryChanges(local, current))
+	case message.TypeServerHelloComplete:
+		// Step 4: The Server sends a Server Hello Complete message.
+		// Server is done sending entryAssigns
+		if c.status == ClientSentHello {
+			c.startSync(true)
+		}
+		c.setStatus(ClientInSync)
+
+		// Step 5: For all Entries the Client recognizes that the Server
+		// did not identify with a Entry Assignment.
+		// we can now send any entries the server should have
+		c.reconcileEntries()
+		c.sendPendingEntries()
 
-			// Step 6: The Client sends a Client Hello Complete message.
+		// Step 6: The Client sends a Client Hello Complete message.
+		// NetworkTables 2.0 has no such message
+		if c.protocolRev != protocolRev2 {
 			msg := message.ClientHelloCompleteFromItems()
-			c.QueueMessage(msg)
-			c.status = ClientInSync
-		case message.TypeEntryUpdate:
-			msg := tempPacket.(*message.EntryUpdate)
-			up := msg.GetUpdate()
-			for _, e := range c.entries {
-				if up.GetID() == e.GetID() {
-					if up.GetType() != e.GetType() {
-						log.Printf("Types differ. Ignoring update")
-						break
-					}
-					switch up.GetType() {
-					case entry.TypeBoolean:
-						eu := up.(*entryupdate.Boolean)
-						e.SetValue(eu.GetValue())
-
-					case entry.TypeDouble:
-					case entry.TypeString:
-					case entry.TypeRaw:
-					case entry.TypeBooleanArr:
-					case entry.TypeDoubleArr:
-					case entry.TypeStringArr:
-					case entry.TypeRPCDef:
-					default:
-						log.Printf("Unknown type! Unable to update entry")
-					}
-					fmt.Printf("%v", e.GetID())
-				}
+			c.queueMessage(msg)
+		}
+		c.synced = true
+	case message.TypeEntryUpdate:
+		msg := tempPacket.(*message.EntryUpdate)
+		up := msg.GetUpdate()
+		e, ok := c.entries.getByID(up.GetRawID())
+		if !ok {
+			break
+		}
+		if up.GetType() != e.GetType() {
+			c.logger.Warnf("client: types differ, ignoring update to %s", e.GetName())
+			break
+		}
+		// An update older than our own value lost the race against
+		// an update we sent. When both have the same sequence number
+		// the server already arbitrated between them, so it wins.
+		if util.SequenceGreater(e.GetSequence(), up.GetSequence()) {
+			c.logger.Debugf("client: stale sequence number, ignoring update to %s", e.GetName())
+			break
+		}
+		// the types match, so the update's value can be applied directly
+		updated, err := copyEntry(e, up.GetSequence(), e.GetFlags(), up.GetValueUnsafe())
+		if err != nil {
+			c.logger.Errorf("client: %s", err)
+			break
+		}
+		c.entries.put(updated)
+		if !reflect.DeepEqual(e.GetValue(), updated.GetValue()) {
+			c.notifyEntry(updated, EventUpdated)
+		}
+	case message.TypeClientHelloComplete:
+		// only expect to get this message on the server
+	case message.TypeKeepAlive:
+		// can be safely ignored
+	case message.TypeClientHello:
+		// only expected on the server
+	case message.TypeProtoUnsupported:
+		// the server closes the connection after telling us which
+		// revision it supports, so the handshake is retried with it
+		msg := tempPacket.(*message.ProtoUnsupported)
+		supported := msg.GetSupportedProto()
+		if supported == c.protocolRev || (supported != protocolRev2 && supported != protocolRev3) {
+			c.logger.Errorf("client: server only supports protocol revision %d.%d", supported[0], supported[1])
+			c.connectionLost(conn)
+			return false
+		}
+		c.connMutex.Lock()
+		c.protocolRev = supported
+		c.connMutex.Unlock()
+		conn.Close()
+		go c.retryHandshake(conn)
+		return false
+	case message.TypeEntryFlagUpdate:
+		msg := tempPacket.(*message.EntryFlagUpdate)
+		up := msg.GetFlagUpdate()
+		var id [2]byte
+		binary.LittleEndian.PutUint16(id[:], up.GetID())
+		e, ok := c.entries.getByID(id)
+		if !ok || e.GetFlags() == up.GetFlags() {
+			break
+		}
+		updated, err := copyEntry(e, e.GetSequence(), up.GetFlags(), e.GetValue())
+		if err != nil {
+			c.logger.Errorf("client: %s", err)
+			break
+		}
+		c.entries.put(updated)
+		c.notifyEntry(updated, EventFlagsChanged)
+	case message.TypeEntryDelete:
+		msg := tempPacket.(*message.EntryDelete)
+		e, ok := c.entries.getByID(msg.GetID())
+		if !ok {
+			break
+		}
+		name := e.GetName()
+		c.entries.delete(name)
+		delete(c.owned, name)
+		delete(c.dirty, name)
+		c.notifyEntry(e, EventDeleted)
+	case message.TypeClearAllEntries:
+		for _, e := range c.entries.all() {
+			c.notifyEntry(e, EventDeleted)
+		}
+		c.entries.clear()
+		c.pending = map[string]bool{}
+		c.owned = map[string]bool{}
+		c.dirty = map[string]bool{}
+	case message.TypeRPCExec:
+		// only expected for procedures this client has registered
+		msg := tempPacket.(*message.RPCExec)
+		c.handleRPCExec(msg)
+	case message.TypeRPCResponse:
+		msg := tempPacket.(*message.RPCResponse)
+		call := rpcCall{id: msg.GetID(), uniqueID: msg.GetUniqueID()}
+		c.rpcMutex.Lock()
+		waiting, ok := c.rpcCalls[call]
+		delete(c.rpcCalls, call)
+		c.rpcMutex.Unlock()
+		if ok {
+			waiting <- msg
+		}
+	default:
+		c.logger.Warnf("client: ===> got unexpected %s", tempPacket.GetType())
+	}
+	return true
+}
+
+// copyEntry returns a copy of the entry with a new sequence number, flags and
+// value. Entries in the store are shared with readers, so they are never
+// changed in place.
+func copyEntry(e entry.IEntry, sequence uint16, flags byte, value interface{}) (entry.IEntry, error) {
+	var sequenceData [2]byte
+	binary.BigEndian.PutUint16(sequenceData[:], sequence)
+	return entry.BuildFromValue(e.GetName(), e.GetType(), e.GetRawID(), sequenceData, flags, value)
+}
+
+// startSync records the entries known before the server starts assigning
+// its entries, so the two can be reconciled once the server is done
+func (c *Client) startSync(firstConnection bool) {
+	// a server that does not remember us after a reconnect has
+	// restarted and lost the values we published
+	c.serverRestarted = c.synced && firstConnection
+	// the returned map is our own, so it can be kept as it is
+	c.previous = c.entries.all()
+	c.assigned = map[string]bool{}
+	c.setStatus(ClientStartingSync)
+}
+
+// reconcileEntries compares the entries known before the handshake with the
+// ones the server assigned. Values we own are sent again if they changed while
+// disconnected, or all of them if the server restarted. Entries we do not own
+// that the server no longer has were deleted while we were away.
+func (c *Client) reconcileEntries() {
+	for name, local := range c.previous {
+		if c.assigned[name] {
+			current, _ := c.entries.get(name)
+			republish := entry.IsPending(local) || (c.owned[name] && (c.dirty[name] || c.serverRestarted))
+			if republish && current.GetType() == local.GetType() {
+				c.republish(local)
+				current, _ = c.entries.get(name)
 			}
-		case message.TypeClientHelloComplete:
-			// only expect to get this message on the server
-		case message.TypeKeepAlive:
-			// can be safely ignored
-		case message.TypeClientHello:
-			// only expected on the server
-		case message.TypeProtoUnsupported:
-			// @todo
-		case message.TypeEntryFlagUpdate:
-			// @todo
-		case message.TypeEntryDelete:
-			// @todo
-		case message.TypeClearAllEntries:
-			// @todo
-		case message.TypeRPCExec:
-			// @todo
-		case message.TypeRPCResponse:
-			// @todo
-		default:
-			fmt.Printf("===> got UNKNOWN\n")
+			c.notifyEntry(current, entryChanges(local, current))
+			continue
+		}
+		if entry.IsPending(local) {
+			// the EntryAssign may have been lost with the connection
+			c.pending[name] = false
+			continue
+		}
+		if !c.owned[name] {
+			c.entries.delete(name)
+			c.notifyEntry(local, EventDeleted)
+			continue
 		}
+		// the server has forgotten an entry we published, so it is created again
+		recreated, err := entry.NewFromValue(name, local.GetType(), local.GetValue())
+		if err != nil {
+			c.logger.Errorf("client: could not republish %s: %s", name, err)
+			continue
+		}
+		recreated.SetFlags(local.GetFlags())
+		c.entries.put(recreated)
+		c.pending[name] = false
 	}
+	for name := range c.assigned {
+		if _, known := c.previous[name]; !known {
+			current, _ := c.entries.get(name)
+			c.notifyEntry(current, EventCreated)
+		}
+	}
+	c.previous = nil
+	c.assigned = nil
+	c.dirty = map[string]bool{}
 }
 
-func updateLastSent() {
-	currentTime := time.Now()
-	lastSent = currentTime.Unix()
+// republish sends our flags and value for an entry the server has assigned,
+// if they differ from the assigned ones
+func (c *Client) republish(local entry.IEntry) {
+	if current, ok := c.entries.get(local.GetName()); ok {
+		c.updateFlags(current, local.GetFlags())
+	}
+	if current, ok := c.entries.get(local.GetName()); ok {
+		c.updateEntry(current, local.GetValue())
+	}
 }
 
-// keepAlive should be run in a Go routine. It sends a
-// the provided packet after the provided time (seconds) have
-// passed between the last packet.
-func (c *Client) keepAlive(packet message.IMessage) {
-	for c.status == ClientInSync {
-		currentTime := time.Now()
-		currentSeconds := currentTime.Unix()
-		if (currentSeconds - lastSent) >= keepAliveTime {
-			go c.QueueMessage(packet)
+// sendPendingEntries sends an EntryAssign for every locally created entry
+// that has not been sent to the server yet
+func (c *Client) sendPendingEntries() {
+	for name, sent := range c.pending {
+		if sent {
+			continue
 		}
+		if pending, ok := c.entries.get(name); ok {
+			c.queueMessage(message.EntryAssignFromEntry(pending))
+		}
+		c.pending[name] = true
+	}
+}
+
+// entryTypeByID returns the type of the entry with the given ID, which is
+// needed to decode NetworkTables 2.0 updates
+func (c *Client) entryTypeByID(id [2]byte) (entry.EntryType, bool) {
+	if e, ok := c.entries.getByID(id); ok {
+		return e.GetType(), true
+	}
+	return 0, false
+}
+
+// getValue fetches the value of the entry at the specified key, which must
+// be of the given type
+func (c *Client) getValue(key string, entryType entry.EntryType) (interface{}, error) {
+	key = util.SanitizeKey(key)
+	e, ok := c.entries.get(key)
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+	if e.GetType() != entryType {
+		return nil, &TypeMismatchError{Key: key, Expected: entryType, Actual: e.GetType()}
 	}
+	return e.GetValue(), nil
 }
 
 // GetBoolean fetches a boolean at the specified key
 func (c *Client) GetBoolean(key string) (bool, error) {
+	value, err := c.getValue(key, entry.TypeBoolean)
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+// GetDouble fetches a double at the specified key
+func (c *Client) GetDouble(key string) (float64, error) {
+	value, err := c.getValue(key, entry.TypeDouble)
+	if err != nil {
+		return 0, err
+	}
+	return value.(float64), nil
+}
+
+// GetString fetches a string at the specified key
+func (c *Client) GetString(key string) (string, error) {
+	value, err := c.getValue(key, entry.TypeString)
+	if err != nil {
+		return "", err
+	}
+	return value.(string), nil
+}
+
+// GetRaw fetches a raw value at the specified key
+func (c *Client) GetRaw(key string) ([]byte, error) {
+	value, err := c.getValue(key, entry.TypeRaw)
+	if err != nil {
+		return nil, err
+	}
+	// the stored value is shared, so the caller gets a copy
+	return append([]byte{}, value.([]byte)...), nil
+}
+
+// GetBooleanArray fetches a boolean array at the specified key
+func (c *Client) GetBooleanArray(key string) ([]bool, error) {
+	value, err := c.getValue(key, entry.TypeBooleanArr)
+	if err != nil {
+		return nil, err
+	}
+	return append([]bool{}, value.([]bool)...), nil
+}
+
+// GetDoubleArray fetches a double array at the specified key
+func (c *Client) GetDoubleArray(key string) ([]float64, error) {
+	value, err := c.getValue(key, entry.TypeDoubleArr)
+	if err != nil {
+		return nil, err
+	}
+	return append([]float64{}, value.([]float64)...), nil
+}
+
+// GetStringArray fetches a string array at the specified key
+func (c *Client) GetStringArray(key string) ([]string, error) {
+	value, err := c.getValue(key, entry.TypeStringArr)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{}, value.([]string)...), nil
+}
+
+// GetBooleanOrDefault fetches a boolean at the specified key, or returns the
+// default value if there is no boolean there
+func (c *Client) GetBooleanOrDefault(key string, defaultValue bool) bool {
+	if value, err := c.GetBoolean(key); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// GetDoubleOrDefault fetches a double at the specified key, or returns the
+// default value if there is no double there
+func (c *Client) GetDoubleOrDefault(key string, defaultValue float64) float64 {
+	if value, err := c.GetDouble(key); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// GetStringOrDefault fetches a string at the specified key, or returns the
+// default value if there is no string there
+func (c *Client) GetStringOrDefault(key string, defaultValue string) string {
+	if value, err := c.GetString(key); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// GetRawOrDefault fetches a raw value at the specified key, or returns the
+// default value if there is no raw value there
+func (c *Client) GetRawOrDefault(key string, defaultValue []byte) []byte {
+	if value, err := c.GetRaw(key); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// GetBooleanArrayOrDefault fetches a boolean array at the specified key, or
+// returns the default value if there is no boolean array there
+func (c *Client) GetBooleanArrayOrDefault(key string, defaultValue []bool) []bool {
+	if value, err := c.GetBooleanArray(key); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// GetDoubleArrayOrDefault fetches a double array at the specified key, or
+// returns the default value if there is no double array there
+func (c *Client) GetDoubleArrayOrDefault(key string, defaultValue []float64) []float64 {
+	if value, err := c.GetDoubleArray(key); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// GetStringArrayOrDefault fetches a string array at the specified key, or
+// returns the default value if there is no string array there
+func (c *Client) GetStringArrayOrDefault(key string, defaultValue []string) []string {
+	if value, err := c.GetStringArray(key); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// PutBoolean creates a boolean entry at the specified key
+func (c *Client) PutBoolean(key string, value bool) error {
+	return c.putEntry(entry.NewBoolean(util.SanitizeKey(key), value))
+}
+
+// PutDouble creates a double entry at the specified key
+func (c *Client) PutDouble(key string, value float64) error {
+	return c.putEntry(entry.NewDouble(util.SanitizeKey(key), value))
+}
+
+// PutString creates a string entry at the specified key
+func (c *Client) PutString(key string, value string) error {
+	return c.putEntry(entry.NewString(util.SanitizeKey(key), value))
+}
+
+// PutRaw creates a raw entry at the specified key
+func (c *Client) PutRaw(key string, value []byte) error {
+	return c.putEntry(entry.NewRaw(util.SanitizeKey(key), value))
+}
+
+// PutBooleanArray creates a boolean array entry at the specified key
+func (c *Client) PutBooleanArray(key string, value []bool) error {
+	newEntry, err := entry.NewBooleanArr(util.SanitizeKey(key), value)
+	if err != nil {
+		return err
+	}
+	return c.putEntry(newEntry)
+}
+
+// PutDoubleArray creates a double array entry at the specified key
+func (c *Client) PutDoubleArray(key string, value []float64) error {
+	newEntry, err := entry.NewDoubleArr(util.SanitizeKey(key), value)
+	if err != nil {
+		return err
+	}
+	return c.putEntry(newEntry)
+}
+
+// PutStringArray creates a string array entry at the specified key
+func (c *Client) PutStringArray(key string, value []string) error {
+	newEntry, err := entry.NewStringArr(util.SanitizeKey(key), value)
+	if err != nil {
+		return err
+	}
+	return c.putEntry(newEntry)
+}
+
+// putEntry stores a new, unassigned entry locally and asks the server to
+// assign it an ID. If the entry is still waiting on its assignment, only the
+// local value is changed and the latest value is kept until the server replies.
+func (c *Client) putEntry(newEntry entry.IEntry) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.putEntryLocked(newEntry)
+}
+
+// putEntryLocked is putEntry for callers already holding the mutex
+func (c *Client) putEntryLocked(newEntry entry.IEntry) error {
+	name := newEntry.GetName()
+	previous, _ := c.entries.get(name)
+	err := c.publishEntry(newEntry)
+	current, _ := c.entries.get(name)
+	c.notifyEntry(current, entryChanges(previous, current)|EventLocal)
+	return err
+}
+
+func (c *Client) publishEntry(newEntry entry.IEntry) error {
+	name := newEntry.GetName()
+	existing, ok := c.entries.get(name)
+	if ok && existing.GetType() != newEntry.GetType() {
+		return &TypeMismatchError{Key: name, Expected: newEntry.GetType(), Actual: existing.GetType()}
+	}
+	if ok && !entry.IsPending(existing) {
+		return c.updateEntry(existing, newEntry.GetValue())
+	}
+	c.entries.put(newEntry)
+	c.owned[name] = true
+	if c.pending[name] {
+		// the EntryAssign was already sent, wait for the server to reply
+		return nil
+	}
+	c.pending[name] = false
+	if c.status != ClientInSync {
+		// sent once the server has finished sending its entries
+		return nil
+	}
+	c.pending[name] = true
+	return c.queueMessage(message.EntryAssignFromEntry(newEntry))
+}
+
+// SetBoolean updates the boolean at the specified key, creating it if needed
+func (c *Client) SetBoolean(key string, value bool) error {
+	return c.setValue(util.SanitizeKey(key), entry.TypeBoolean, value)
+}
+
+// SetDouble updates the double at the specified key, creating it if needed
+func (c *Client) SetDouble(key string, value float64) error {
+	return c.setValue(util.SanitizeKey(key), entry.TypeDouble, value)
+}
+
+// SetString updates the string at the specified key, creating it if needed
+func (c *Client) SetString(key string, value string) error {
+	return c.setValue(util.SanitizeKey(key), entry.TypeString, value)
+}
+
+// SetRaw updates the raw value at the specified key, creating it if needed
+func (c *Client) SetRaw(key string, value []byte) error {
+	return c.setValue(util.SanitizeKey(key), entry.TypeRaw, value)
+}
+
+// SetBooleanArray updates the boolean array at the specified key, creating it if needed
+func (c *Client) SetBooleanArray(key string, value []bool) error {
+	return c.setValue(util.SanitizeKey(key), entry.TypeBooleanArr, value)
+}
+
+// SetDoubleArray updates the double array at the specified key, creating it if needed
+func (c *Client) SetDoubleArray(key string, value []float64) error {
+	return c.setValue(util.SanitizeKey(key), entry.TypeDoubleArr, value)
+}
+
+// SetStringArray updates the string array at the specified key, creating it if needed
+func (c *Client) SetStringArray(key string, value []string) error {
+	return c.setValue(util.SanitizeKey(key), entry.TypeStringArr, value)
+}
+
+// setValue updates an existing entry, or creates it when the server does not know about it yet
+func (c *Client) setValue(key string, entryType entry.EntryType, value interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	existing, ok := c.entries.get(key)
+	if ok && existing.GetType() != entryType {
+		return &TypeMismatchError{Key: key, Expected: entryType, Actual: existing.GetType()}
+	}
+	if !ok || entry.IsPending(existing) {
+		newEntry, err := entry.NewFromValue(key, entryType, value)
+		if err != nil {
+			return err
+		}
+		return c.putEntryLocked(newEntry)
+	}
+	err := c.updateEntry(existing, value)
+	current, _ := c.entries.get(key)
+	c.notifyEntry(current, entryChanges(existing, current)|EventLocal)
+	return err
+}
+
+// updateEntry applies a new value to an assigned entry, bumps its sequence
+// number and sends the resulting EntryUpdate to the server
+func (c *Client) updateEntry(existing entry.IEntry, value interface{}) error {
+	if reflect.DeepEqual(existing.GetValue(), value) {
+		// only send an update if the value has changed
+		return nil
+	}
+	updated, err := copyEntry(existing, existing.GetSequence()+1, existing.GetFlags(), value)
+	if err != nil {
+		return err
+	}
+	c.entries.put(updated)
+	c.owned[updated.GetName()] = true
+	if c.status != ClientInSync {
+		// sent once the connection to the server is synchronised again
+		c.dirty[updated.GetName()] = true
+		return nil
+	}
+	update, err := entryupdate.BuildFromEntry(updated)
+	if err != nil {
+		return err
+	}
+	return c.queueMessage(message.EntryUpdateFromUpdate(update))
+}
+
+// SetPersistent marks the entry at the specified key as persistent, or clears
+// the flag. The server saves persistent entries and restores them on restart.
+func (c *Client) SetPersistent(key string, persistent bool) error {
+	key = util.SanitizeKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	existing, ok := c.entries.get(key)
+	if !ok {
+		return &KeyNotFoundError{Key: key}
+	}
+	flags := existing.GetFlags()
+	if persistent {
+		flags |= entry.FlagPersistent
+	} else {
+		flags &^= entry.FlagPersistent
+	}
+	if flags == existing.GetFlags() {
+		return nil
+	}
+	err := c.updateFlags(existing, flags)
+	current, _ := c.entries.get(key)
+	c.notifyEntry(current, EventFlagsChanged|EventLocal)
+	return err
+}
+
+// ClearPersistent clears the persistent flag of the entry at the specified key
+func (c *Client) ClearPersistent(key string) error {
+	return c.SetPersistent(key, false)
+}
+
+// updateFlags applies new flags to an entry and sends the resulting
+// EntryFlagUpdate to the server. The flags of an entry still waiting on its
+// ID are sent with its EntryAssign, or once the server has assigned it.
+func (c *Client) updateFlags(existing entry.IEntry, flags byte) error {
+	if existing.GetFlags() == flags {
+		return nil
+	}
+	updated, err := copyEntry(existing, existing.GetSequence(), flags, existing.GetValue())
+	if err != nil {
+		return err
+	}
+	c.entries.put(updated)
+	if entry.IsPending(existing) {
+		return nil
+	}
+	c.owned[existing.GetName()] = true
+	if c.status != ClientInSync {
+		// sent once the connection to the server is synchronised again
+		c.dirty[existing.GetName()] = true
+		return nil
+	}
+	return c.queueMessage(message.EntryFlagUpdateFromItems(existing.GetRawID(), flags))
+}
+
+// Delete removes the entry at the specified key locally and from the server
+func (c *Client) Delete(key string) error {
 	key = util.SanitizeKey(key)
-	entry, ok := c.entries[key]
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	existing, ok := c.entries.get(key)
 	if !ok {
-		return false, fmt.Errorf("key is missing")
+		return &KeyNotFoundError{Key: key}
+	}
+	c.entries.delete(key)
+	delete(c.owned, key)
+	delete(c.dirty, key)
+	c.notifyEntry(existing, EventDeleted|EventLocal)
+	if entry.IsPending(existing) {
+		// the server has not assigned an ID, so there is nothing to delete remotely
+		delete(c.pending, key)
+		return nil
+	}
+	return c.queueMessage(message.EntryDeleteFromItems(existing.GetRawID()))
+}
+
+// DeleteAll removes every entry locally and from the server
+func (c *Client) DeleteAll() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, e := range c.entries.all() {
+		c.notifyEntry(e, EventDeleted|EventLocal)
+	}
+	c.entries.clear()
+	c.pending = map[string]bool{}
+	c.owned = map[string]bool{}
+	c.dirty = map[string]bool{}
+	return c.queueMessage(message.ClearAllEntriesFromItems())
+}
+
+// CallRPC executes the remote procedure defined at the specified key and
+// waits for its results. A nil parameter is replaced by the parameter's
+// default value. The call is abandoned when the context is cancelled.
+func (c *Client) CallRPC(ctx context.Context, key string, params []interface{}) ([]interface{}, error) {
+	key = util.SanitizeKey(key)
+	if c.GetStatus() != ClientInSync {
+		return nil, errors.New("client: not connected to a server")
+	}
+	existing, ok := c.entries.get(key)
+	if !ok {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+	if existing.GetType() != entry.TypeRPCDef {
+		return nil, &TypeMismatchError{Key: key, Expected: entry.TypeRPCDef, Actual: existing.GetType()}
+	}
+	definition := existing.GetValue().(entry.RPCDefinition)
+	paramData, err := definition.EncodeParams(params)
+	if err != nil {
+		return nil, err
 	}
 
-	return entry.GetValue().(bool), nil
+	response := make(chan *message.RPCResponse, 1)
+	call := rpcCall{id: existing.GetRawID()}
+	c.rpcMutex.Lock()
+	c.rpcUniqueID++
+	binary.BigEndian.PutUint16(call.uniqueID[:], c.rpcUniqueID)
+	c.rpcCalls[call] = response
+	c.rpcMutex.Unlock()
+	defer func() {
+		c.rpcMutex.Lock()
+		delete(c.rpcCalls, call)
+		c.rpcMutex.Unlock()
+	}()
+
+	err = c.QueueMessage(message.RPCExecFromItems(call.id, call.uniqueID, paramData))
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-response:
+		return definition.DecodeResults(msg.GetResults())
+	}
 }
 
-//Set function to be called when robot connects/disconnects
-//func (c Client) AddRobotConnectionListener(callback func()) {}
-//func (c Client) AddKeyListener(key string, callback func()) {}
+// RegisterRPC publishes a remote procedure definition at the specified key
+// and serves calls to it with the handler. Each call runs in its own goroutine.
+func (c *Client) RegisterRPC(key string, definition entry.RPCDefinition, handler RPCHandler) error {
+	key = util.SanitizeKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if existing, ok := c.entries.get(key); ok && !entry.IsPending(existing) {
+		return fmt.Errorf("client: entry %s already exists", key)
+	}
+	newEntry, err := entry.NewRPCDef(key, definition)
+	if err != nil {
+		return err
+	}
+	c.rpcMutex.Lock()
+	c.rpcHandlers[key] = handler
+	c.rpcMutex.Unlock()
+	return c.putEntryLocked(newEntry)
+}
 
-func (c Client) GetKeys(prefix string) []string {
+// UnregisterRPC stops serving the remote procedure at the specified key and deletes its definition
+func (c *Client) UnregisterRPC(key string) error {
+	key = util.SanitizeKey(key)
+	c.rpcMutex.Lock()
+	delete(c.rpcHandlers, key)
+	c.rpcMutex.Unlock()
+	return c.Delete(key)
+}
+
+// handleRPCExec dispatches a call to one of the procedures served by this client
+func (c *Client) handleRPCExec(exec *message.RPCExec) {
+	e, ok := c.entries.getByID(exec.GetID())
+	if !ok {
+		c.logger.Warnf("rpc: call to unknown entry %#x", exec.GetID())
+		return
+	}
+	name := e.GetName()
+	c.rpcMutex.Lock()
+	handler, ok := c.rpcHandlers[name]
+	c.rpcMutex.Unlock()
+	if !ok || e.GetType() != entry.TypeRPCDef {
+		c.logger.Warnf("rpc: no handler registered for %s", name)
+		return
+	}
+	definition := e.GetValue().(entry.RPCDefinition)
+	go func() {
+		response := executeRPC(c.logger, definition, handler, exec)
+		if response != nil {
+			c.QueueMessage(response)
+		}
+	}()
+}
+
+func (c *Client) GetKeys(prefix string) []string {
 	keys := []string{}
-	for k, _ := range c.entries {
+	for k, _ := range c.entries.all() {
 		if prefix == "" || strings.HasPrefix(k, prefix) {
 			keys = append(keys, k)
 		}
@@ -287,13 +1482,17 @@ func (c Client) GetKeys(prefix string) []string {
 }
 
 // Determines whether the given key is in this table.
-func (c Client) ContainsKey(key string) bool {
-	_, ok := c.entries[key]
+func (c *Client) ContainsKey(key string) bool {
+	_, ok := c.entries.get(key)
 	return ok
 }
 
-func (c Client) GetEntry(key string) interface{} {
-	e := c.entries[key]
+// GetEntry returns the value of the entry with the key, or nil if there is none
+func (c *Client) GetEntry(key string) interface{} {
+	e, ok := c.entries.get(key)
+	if !ok {
+		return nil
+	}
 	return e.GetValue()
 }
 
@@ -303,9 +1502,9 @@ type SnapShotEntry struct {
 	Datatype string `json:"type"`
 }
 
-func (c Client) GetSnapshot(prefix string) []SnapShotEntry {
+func (c *Client) GetSnapshot(prefix string) []SnapShotEntry {
 	keys := []SnapShotEntry{}
-	for k, v := range c.entries {
+	for k, v := range c.entries.all() {
 		if prefix == "" || strings.HasPrefix(k, prefix) {
 			valueStr := fmt.Sprintf("%#v", v.GetValue())
 			valueByt, err := json.Marshal(v.GetValue())
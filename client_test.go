@@ -0,0 +1,395 @@
+package frcntgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/entryupdate"
+	"github.com/techplexengineer/frc-networktables-go/message"
+)
+
+func TestClientFallsBackToRev2(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// a NetworkTables 2.0 server rejects the 3.0 Client Hello, and accepts
+	// the 2.0 one with an empty Server Hello Complete
+	hellos := make(chan [2]byte, 3)
+	connections := make(chan net.Conn, 3)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			connections <- conn
+			hello := make([]byte, 3)
+			if _, err := io.ReadFull(conn, hello); err != nil {
+				conn.Close()
+				continue
+			}
+			rev := [2]byte{hello[1], hello[2]}
+			hellos <- rev
+			if rev != protocolRev2 {
+				conn.Write([]byte{0x02, 0x02, 0x00})
+				conn.Close()
+				continue
+			}
+			conn.Write([]byte{0x03})
+		}
+	}()
+
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	client, err := NewClient("127.0.0.1", port, WithLogger(quietLogger), WithReconnect(10*time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.WaitForSync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range [][2]byte{protocolRev3, protocolRev2} {
+		if rev := <-hellos; rev != want {
+			t.Fatalf("client sent revision %x, want %x", rev, want)
+		}
+	}
+
+	// the client tries the newer revision again once it has to reconnect
+	<-connections
+	(<-connections).Close()
+	select {
+	case rev := <-hellos:
+		if rev != protocolRev3 {
+			t.Fatalf("client reconnected with revision %x, want %x", rev, protocolRev3)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("client never reconnected")
+	}
+}
+
+func TestClientReadsDuringUpdates(t *testing.T) {
+	server, port := startServer(t)
+	const keys = 20
+	for i := 0; i < keys; i++ {
+		server.SetValue(fmt.Sprintf("/values/%d", i), entry.TypeDouble, float64(0))
+	}
+	client := startClient(t, port)
+
+	// the server streams Entry Updates to the client while it is read from,
+	// pausing now and then so that the client's queue never overflows
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 2000; i++ {
+			server.SetValue(fmt.Sprintf("/values/%d", i%keys), entry.TypeDouble, float64(i))
+			if i%keys == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				key := fmt.Sprintf("/values/%d", r%keys)
+				if _, err := client.GetDouble(key); err != nil {
+					t.Errorf("reading %s: %s", key, err)
+					return
+				}
+				if !client.ContainsKey(key) {
+					t.Errorf("client lost %s", key)
+					return
+				}
+				if n := len(client.GetKeys("/values")); n != keys {
+					t.Errorf("client has %d keys, want %d", n, keys)
+					return
+				}
+				if n := len(client.GetSnapshot("/values")); n != keys {
+					t.Errorf("snapshot has %d entries, want %d", n, keys)
+					return
+				}
+				runtime.Gosched()
+			}
+		}(r)
+	}
+	readers.Wait()
+	eventually(t, func() bool {
+		value, _ := client.GetDouble(fmt.Sprintf("/values/%d", 2000%keys))
+		return value == 2000
+	}, "the client never got the last update")
+}
+
+func TestClientsOfSeparateServers(t *testing.T) {
+	firstServer, firstPort := startServer(t)
+	secondServer, secondPort := startServer(t)
+	first := startClient(t, firstPort)
+	second := startClient(t, secondPort)
+
+	first.SetDouble("/first", 1)
+	second.SetDouble("/second", 2)
+	first.Flush()
+	second.Flush()
+	eventually(t, func() bool {
+		return firstServer.GetEntry("/first") != nil && secondServer.GetEntry("/second") != nil
+	}, "the servers never got their clients' values")
+
+	// each client's entries and queue belong to it alone
+	if firstServer.GetEntry("/second") != nil {
+		t.Fatal("the first server got the second client's entry")
+	}
+	if secondServer.GetEntry("/first") != nil {
+		t.Fatal("the second server got the first client's entry")
+	}
+	if first.ContainsKey("/second") || second.ContainsKey("/first") {
+		t.Fatal("a client got the other client's entry")
+	}
+}
+
+func TestClientIdleBeforeFirstConnection(t *testing.T) {
+	// nothing listens on port 1, so the client is left without a connection
+	// for several keep alive intervals
+	client, err := NewClient("127.0.0.1", "1", WithLogger(quietLogger),
+		WithReconnect(time.Second, 5*time.Second), WithKeepAlive(10*time.Millisecond, 50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	time.Sleep(100 * time.Millisecond)
+	if status := client.GetStatus(); status != ClientDisconnected {
+		t.Fatalf("client is %v without a server", status)
+	}
+}
+
+func TestClientKeepAliveTimeoutMustExceedInterval(t *testing.T) {
+	_, port := startServer(t)
+	if _, err := NewClient("127.0.0.1", port, WithLogger(quietLogger), WithKeepAlive(time.Second, time.Second)); err == nil {
+		t.Fatal("client accepted a keep alive timeout equal to its interval")
+	}
+}
+
+func TestClientRejectsInvalidReconnectDelays(t *testing.T) {
+	_, port := startServer(t)
+	tests := []struct {
+		minDelay, maxDelay time.Duration
+	}{
+		{0, time.Second},
+		{-time.Second, time.Second},
+		{time.Second, 500 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if _, err := NewClient("127.0.0.1", port, WithLogger(quietLogger), WithReconnect(test.minDelay, test.maxDelay)); err == nil {
+			t.Errorf("client accepted reconnect delays from %s to %s", test.minDelay, test.maxDelay)
+		}
+	}
+	client, err := NewClient("127.0.0.1", port, WithLogger(quietLogger), WithReconnect(time.Second, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+}
+
+func TestClientStaysConnectedToIdleServer(t *testing.T) {
+	_, port := startServer(t, WithServerKeepAlive(20*time.Millisecond))
+	client := startClient(t, port, WithKeepAlive(20*time.Millisecond, 200*time.Millisecond))
+	// the server's KeepAlives keep the client from timing out while nothing
+	// else is sent
+	time.Sleep(time.Second)
+	if status := client.GetStatus(); status != ClientInSync {
+		t.Fatalf("client is %v after idling", status)
+	}
+}
+
+func TestClientMissingKeys(t *testing.T) {
+	_, port := startServer(t)
+	client := startClient(t, port)
+	if value := client.GetEntry("/missing"); value != nil {
+		t.Fatalf("GetEntry of a missing key returned %v", value)
+	}
+	if value := client.GetDoubleOrDefault("", 1); value != 1 {
+		t.Fatalf("GetDoubleOrDefault of the empty key returned %v", value)
+	}
+	if client.ContainsKey("") {
+		t.Fatal("client contains the empty key")
+	}
+}
+
+func TestClientRejectsLongArrays(t *testing.T) {
+	_, port := startServer(t)
+	client := startClient(t, port)
+	longest := make([]float64, codec.MaxArrayLength)
+	tooLong := make([]float64, codec.MaxArrayLength+1)
+	if err := client.PutDoubleArray("/array", tooLong); err == nil {
+		t.Fatal("PutDoubleArray accepted an array that is too long")
+	}
+	if client.ContainsKey("/array") {
+		t.Fatal("client stored an array that is too long")
+	}
+	if err := client.PutDoubleArray("/array", longest); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetDoubleArray("/array", tooLong); err == nil {
+		t.Fatal("SetDoubleArray accepted an array that is too long")
+	}
+	if value, _ := client.GetDoubleArray("/array"); len(value) != codec.MaxArrayLength {
+		t.Fatalf("array holds %d elements, want %d", len(value), codec.MaxArrayLength)
+	}
+	if err := client.PutStringArray("/strings", make([]string, codec.MaxArrayLength+1)); err == nil {
+		t.Fatal("PutStringArray accepted an array that is too long")
+	}
+	if err := client.SetBooleanArray("/booleans", make([]bool, codec.MaxArrayLength+1)); err == nil {
+		t.Fatal("SetBooleanArray accepted an array that is too long")
+	}
+}
+
+// startStalledServer serves a fake server that completes the handshake and
+// then never reads from the client again. It sends a KeepAlive at every tick
+// of the interval, if one is given.
+func startStalledServer(t *testing.T, keepAliveInterval time.Duration) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	hello, err := message.ServerHelloFromItems(0x00, codec.EncodeString("stalled server"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopped := make(chan struct{})
+	t.Cleanup(func() { close(stopped) })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write(append(hello.CompressToBytes(), message.ServerHelloCompleteFromItems().CompressToBytes()...))
+		var ticks <-chan time.Time
+		if keepAliveInterval > 0 {
+			ticker := time.NewTicker(keepAliveInterval)
+			defer ticker.Stop()
+			ticks = ticker.C
+		}
+		for {
+			select {
+			case <-stopped:
+				return
+			case <-ticks:
+				conn.Write(message.KeepAliveFromItems().CompressToBytes())
+			}
+		}
+	}()
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// fillConnection puts large values until the client refuses them or the
+// limit is reached
+func fillConnection(client *Client) {
+	value := make([]byte, 64*1024)
+	for i := 0; i < 1024; i++ {
+		if err := client.PutRaw(fmt.Sprintf("/fill/%d", i), value); err != nil {
+			return
+		}
+	}
+}
+
+func TestClientCloseWhileServerStopsReading(t *testing.T) {
+	port := startStalledServer(t, 0)
+	client := startClient(t, port, WithKeepAlive(time.Second, 0), WithFlushPeriod(0))
+	go fillConnection(client)
+	// give the writes time to fill the connection and block
+	time.Sleep(500 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		client.Close()
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close blocked on a server that stopped reading")
+	}
+}
+
+func TestClientDropsConnectionWhenWritesFail(t *testing.T) {
+	// the server's KeepAlives keep the connection from timing out, so only
+	// the failed writes can tell the client that it is gone
+	port := startStalledServer(t, 20*time.Millisecond)
+	client := startClient(t, port, WithKeepAlive(100*time.Millisecond, 300*time.Millisecond), WithFlushPeriod(0))
+	go fillConnection(client)
+	eventually(t, func() bool { return client.GetStatus() == ClientDisconnected }, "client kept a connection it could not write to")
+}
+
+func TestClientIgnoresStaleUpdates(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	hello, err := message.ServerHelloFromItems(0x00, codec.EncodeString("fake server"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	speed := [2]byte{0x00, 0x01}
+	angle := [2]byte{0x00, 0x02}
+	done := [2]byte{0x00, 0x03}
+	// each entry gets an update with a newer sequence number followed by a
+	// stale one, and the angle's sequence number wraps around in between
+	messages := []message.IMessage{
+		hello,
+		message.EntryAssignFromEntry(entry.DoubleFromValue("/speed", speed, [2]byte{0x00, 0x05}, 0x00, 1)),
+		message.EntryAssignFromEntry(entry.DoubleFromValue("/angle", angle, [2]byte{0xFF, 0xFE}, 0x00, 1)),
+		message.EntryAssignFromEntry(entry.BooleanFromValue("/done", done, [2]byte{0x00, 0x00}, 0x00, false)),
+		message.ServerHelloCompleteFromItems(),
+		message.EntryUpdateFromUpdate(entryupdate.DoubleFromValue(speed, [2]byte{0x00, 0x06}, 2)),
+		message.EntryUpdateFromUpdate(entryupdate.DoubleFromValue(speed, [2]byte{0x00, 0x04}, 3)),
+		message.EntryUpdateFromUpdate(entryupdate.DoubleFromValue(angle, [2]byte{0x00, 0x01}, 2)),
+		message.EntryUpdateFromUpdate(entryupdate.DoubleFromValue(angle, [2]byte{0xFF, 0xFF}, 3)),
+		message.EntryUpdateFromUpdate(entryupdate.BooleanFromValue(done, [2]byte{0x00, 0x01}, true)),
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for _, msg := range messages {
+			conn.Write(msg.CompressToBytes())
+		}
+		io.Copy(io.Discard, conn)
+	}()
+
+	client := startClient(t, strconv.Itoa(listener.Addr().(*net.TCPAddr).Port))
+	// the client handles updates in order, so once the last one has arrived
+	// so have the others
+	eventually(t, func() bool {
+		value, _ := client.GetBoolean("/done")
+		return value
+	}, "the client never got the last update")
+	for _, key := range []string{"/speed", "/angle"} {
+		if value, _ := client.GetDouble(key); value != 2 {
+			t.Errorf("%s is %v, want the newer update's 2", key, value)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/techplexengineer/frc-networktables-go"
 	"time"
@@ -14,13 +15,13 @@ func main() {
 	}
 
 	fmt.Printf("Waiting for initial sync...\n")
-	for client.GetStatus() != frcntgo.ClientInSync {
-		time.Sleep(500 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.WaitForSync(ctx); err != nil {
+		panic(err)
 	}
 	fmt.Printf("Initial sync complete.\n")
 
-	//time.Sleep(5 * time.Second) //@todo really need to wait for the client to enter the sync state
-
 	isRed, err := client.GetBoolean("/bool")
 	if err != nil {
 		panic(err)
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"github.com/techplexengineer/frc-networktables-go"
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"time"
+)
+
+func main() {
+
+	server := frcntgo.NewServer("frc-nt-golang-server")
+
+	err := server.SetValue("/bool", entry.TypeBoolean, true)
+	if err != nil {
+		panic(err)
+	}
+
+	// restore the persistent entries and save them back whenever they change
+	err = server.PersistTo("networktables.ini", time.Second)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Listening on port 1735...\n")
+	err = server.ListenAndServe("0.0.0.0", "1735")
+	if err != nil {
+		panic(err)
+	}
+}
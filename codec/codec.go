@@ -0,0 +1,218 @@
+// Package codec encodes and decodes the values of NetworkTables 3.0 entries.
+// The wire format of every value type is defined here once, and shared by
+// entries, entry updates and remote procedure calls.
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/techplexengineer/frc-networktables-go/util"
+)
+
+const (
+	boolFalse byte = 0x00
+	boolTrue  byte = 0x01
+
+	// MaxArrayLength is the number of elements an array value can hold, as
+	// its length is sent as a single byte. Longer arrays can not be encoded.
+	MaxArrayLength = math.MaxUint8
+)
+
+// FromBytes decodes a value that takes up all of data with the read function
+func FromBytes[T any](data []byte, read func(io.Reader) (T, error)) (T, error) {
+	reader := bytes.NewReader(data)
+	value, err := read(reader)
+	if err == nil && reader.Len() != 0 {
+		err = fmt.Errorf("codec: %d bytes left after the value", reader.Len())
+	}
+	return value, err
+}
+
+// ReadByte reads a single byte. Readers that are also an io.ByteReader, such
+// as a bufio.Reader, are read without allocating.
+func ReadByte(reader io.Reader) (byte, error) {
+	if byteReader, ok := reader.(io.ByteReader); ok {
+		return byteReader.ReadByte()
+	}
+	var data [1]byte
+	_, err := io.ReadFull(reader, data[:])
+	return data[0], err
+}
+
+// ReadBoolean reads a boolean, sent as a single byte
+func ReadBoolean(reader io.Reader) (bool, error) {
+	data, err := ReadByte(reader)
+	if err != nil {
+		return false, err
+	}
+	return data == boolTrue, nil
+}
+
+// EncodeBoolean returns the wire form of a boolean
+func EncodeBoolean(value bool) []byte {
+	if value {
+		return []byte{boolTrue}
+	}
+	return []byte{boolFalse}
+}
+
+// ReadDouble reads a double, sent as a big endian IEEE 754 double
+func ReadDouble(reader io.Reader) (float64, error) {
+	if byteReader, ok := reader.(io.ByteReader); ok {
+		var bits uint64
+		for i := 0; i < 8; i++ {
+			data, err := byteReader.ReadByte()
+			if err == io.EOF && i > 0 {
+				// running out part way through is reported like io.ReadFull does
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return 0, err
+			}
+			bits = bits<<8 | uint64(data)
+		}
+		return math.Float64frombits(bits), nil
+	}
+	var data [8]byte
+	if _, err := io.ReadFull(reader, data[:]); err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(data[:])), nil
+}
+
+// EncodeDouble returns the wire form of a double
+func EncodeDouble(value float64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, math.Float64bits(value))
+	return data
+}
+
+// ReadString reads a string, sent as its LEB128 encoded length followed by its UTF-8 bytes
+func ReadString(reader io.Reader) (string, error) {
+	data, err := ReadRaw(reader)
+	return string(data), err
+}
+
+// EncodeString returns the wire form of a string
+func EncodeString(value string) []byte {
+	return append(util.AppendULeb128(make([]byte, 0, len(value)+5), uint32(len(value))), value...)
+}
+
+// ReadRaw reads a raw value, sent as its LEB128 encoded length followed by its bytes
+func ReadRaw(reader io.Reader) ([]byte, error) {
+	length, _, err := util.ReadULeb128(reader)
+	if err != nil {
+		return nil, err
+	}
+	return readBytes(reader, int(length))
+}
+
+// EncodeRaw returns the wire form of a raw value
+func EncodeRaw(value []byte) []byte {
+	return append(util.AppendULeb128(make([]byte, 0, len(value)+5), uint32(len(value))), value...)
+}
+
+// readArrayLength reads the single byte element count of an array
+func readArrayLength(reader io.Reader) (int, error) {
+	length, err := ReadByte(reader)
+	return int(length), err
+}
+
+// checkArrayLength returns an error if an array is too long to be sent
+func checkArrayLength(length int) error {
+	if length > MaxArrayLength {
+		return fmt.Errorf("codec: array of %d elements is longer than %d", length, MaxArrayLength)
+	}
+	return nil
+}
+
+// ReadBooleanArray reads an array of booleans, sent as its element count
+// followed by each boolean
+func ReadBooleanArray(reader io.Reader) ([]bool, error) {
+	length, err := readArrayLength(reader)
+	if err != nil {
+		return nil, err
+	}
+	value := make([]bool, length)
+	for index := range value {
+		if value[index], err = ReadBoolean(reader); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// EncodeBooleanArray returns the wire form of an array of booleans, which may
+// hold at most MaxArrayLength elements
+func EncodeBooleanArray(value []bool) ([]byte, error) {
+	if err := checkArrayLength(len(value)); err != nil {
+		return nil, err
+	}
+	encoded := []byte{byte(len(value))}
+	for _, element := range value {
+		encoded = append(encoded, EncodeBoolean(element)...)
+	}
+	return encoded, nil
+}
+
+// ReadDoubleArray reads an array of doubles, sent as its element count
+// followed by each double
+func ReadDoubleArray(reader io.Reader) ([]float64, error) {
+	length, err := readArrayLength(reader)
+	if err != nil {
+		return nil, err
+	}
+	value := make([]float64, length)
+	for index := range value {
+		if value[index], err = ReadDouble(reader); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// EncodeDoubleArray returns the wire form of an array of doubles, which may
+// hold at most MaxArrayLength elements
+func EncodeDoubleArray(value []float64) ([]byte, error) {
+	if err := checkArrayLength(len(value)); err != nil {
+		return nil, err
+	}
+	encoded := []byte{byte(len(value))}
+	for _, element := range value {
+		encoded = append(encoded, EncodeDouble(element)...)
+	}
+	return encoded, nil
+}
+
+// ReadStringArray reads an array of strings, sent as its element count
+// followed by each string
+func ReadStringArray(reader io.Reader) ([]string, error) {
+	length, err := readArrayLength(reader)
+	if err != nil {
+		return nil, err
+	}
+	value := make([]string, length)
+	for index := range value {
+		if value[index], err = ReadString(reader); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// EncodeStringArray returns the wire form of an array of strings, which may
+// hold at most MaxArrayLength elements
+func EncodeStringArray(value []string) ([]byte, error) {
+	if err := checkArrayLength(len(value)); err != nil {
+		return nil, err
+	}
+	encoded := []byte{byte(len(value))}
+	for _, element := range value {
+		encoded = append(encoded, EncodeString(element)...)
+	}
+	return encoded, nil
+}
@@ -0,0 +1,134 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decoder adapts a read function of any value type to the test table
+func decoder[T any](read func(io.Reader) (T, error)) func(io.Reader) (interface{}, error) {
+	return func(reader io.Reader) (interface{}, error) {
+		return read(reader)
+	}
+}
+
+// mustEncode returns the encoding of an array the test knows to be short enough
+func mustEncode(encoded []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}
+
+// repeat returns a slice holding the value count times
+func repeat[T any](value T, count int) []T {
+	values := make([]T, count)
+	for index := range values {
+		values[index] = value
+	}
+	return values
+}
+
+func TestRoundTrip(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	tests := []struct {
+		name    string
+		encoded []byte
+		read    func(io.Reader) (interface{}, error)
+		want    interface{}
+	}{
+		{"false", EncodeBoolean(false), decoder(ReadBoolean), false},
+		{"true", EncodeBoolean(true), decoder(ReadBoolean), true},
+		{"zero double", EncodeDouble(0), decoder(ReadDouble), 0.0},
+		{"negative double", EncodeDouble(-1.5), decoder(ReadDouble), -1.5},
+		{"largest double", EncodeDouble(math.MaxFloat64), decoder(ReadDouble), math.MaxFloat64},
+		{"infinite double", EncodeDouble(math.Inf(-1)), decoder(ReadDouble), math.Inf(-1)},
+		{"empty string", EncodeString(""), decoder(ReadString), ""},
+		{"string", EncodeString("/drive/speed"), decoder(ReadString), "/drive/speed"},
+		{"unicode string", EncodeString("vitesse ➜ ∞"), decoder(ReadString), "vitesse ➜ ∞"},
+		{"string with two byte length", EncodeString(long), decoder(ReadString), long},
+		{"string with three byte length", EncodeString(strings.Repeat("y", 1<<14)), decoder(ReadString), strings.Repeat("y", 1<<14)},
+		{"empty raw", EncodeRaw([]byte{}), decoder(ReadRaw), []byte{}},
+		{"raw with two byte length", EncodeRaw(bytes.Repeat([]byte{0xff}, 300)), decoder(ReadRaw), bytes.Repeat([]byte{0xff}, 300)},
+		{"empty boolean array", mustEncode(EncodeBooleanArray([]bool{})), decoder(ReadBooleanArray), []bool{}},
+		{"boolean array", mustEncode(EncodeBooleanArray([]bool{true, false})), decoder(ReadBooleanArray), []bool{true, false}},
+		{"longest boolean array", mustEncode(EncodeBooleanArray(repeat(true, MaxArrayLength))), decoder(ReadBooleanArray), repeat(true, MaxArrayLength)},
+		{"empty double array", mustEncode(EncodeDoubleArray([]float64{})), decoder(ReadDoubleArray), []float64{}},
+		{"double array", mustEncode(EncodeDoubleArray([]float64{1, -2.25})), decoder(ReadDoubleArray), []float64{1, -2.25}},
+		{"longest double array", mustEncode(EncodeDoubleArray(repeat(0.5, MaxArrayLength))), decoder(ReadDoubleArray), repeat(0.5, MaxArrayLength)},
+		{"empty string array", mustEncode(EncodeStringArray([]string{})), decoder(ReadStringArray), []string{}},
+		{"string array", mustEncode(EncodeStringArray([]string{"", "a", long})), decoder(ReadStringArray), []string{"", "a", long}},
+		{"longest string array", mustEncode(EncodeStringArray(repeat(long, MaxArrayLength))), decoder(ReadStringArray), repeat(long, MaxArrayLength)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := FromBytes(test.encoded, test.read)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("decoded %v, want %v", got, test.want)
+			}
+			if _, err := FromBytes(append(test.encoded, 0x00), test.read); err == nil {
+				t.Error("a trailing byte was accepted")
+			}
+			for _, length := range []int{0, 1, len(test.encoded) / 2, len(test.encoded) - 1} {
+				if length >= len(test.encoded) {
+					continue
+				}
+				if _, err := FromBytes(test.encoded[:length], test.read); err == nil {
+					t.Errorf("the first %d of %d bytes were accepted", length, len(test.encoded))
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeStringLength(t *testing.T) {
+	// the length of a string is sent as an unsigned LEB128 value
+	tests := []struct {
+		length       int
+		prefixLength int
+	}{
+		{0, 1},
+		{127, 1},
+		{128, 2},
+		{1<<14 - 1, 2},
+		{1 << 14, 3},
+	}
+	for _, test := range tests {
+		encoded := EncodeString(strings.Repeat("z", test.length))
+		if prefix := len(encoded) - test.length; prefix != test.prefixLength {
+			t.Errorf("string of %d bytes has a %d byte length, want %d", test.length, prefix, test.prefixLength)
+		}
+	}
+}
+
+func TestEncodeArrayTooLong(t *testing.T) {
+	if _, err := EncodeBooleanArray(make([]bool, MaxArrayLength+1)); err == nil {
+		t.Error("EncodeBooleanArray accepted an array that is too long")
+	}
+	if _, err := EncodeDoubleArray(make([]float64, MaxArrayLength+1)); err == nil {
+		t.Error("EncodeDoubleArray accepted an array that is too long")
+	}
+	if _, err := EncodeStringArray(make([]string, MaxArrayLength+1)); err == nil {
+		t.Error("EncodeStringArray accepted an array that is too long")
+	}
+}
+
+func TestReadLongerThanLimit(t *testing.T) {
+	reader := LimitReader(bytes.NewReader(EncodeString(strings.Repeat("x", 300))), 100)
+	_, err := ReadString(reader)
+	var lengthErr *LengthError
+	if !errors.As(err, &lengthErr) {
+		t.Fatalf("got %v, want a LengthError", err)
+	}
+	if lengthErr.Length != 300 || lengthErr.MaxLength != 100 {
+		t.Fatalf("got a LengthError for %d of %d bytes", lengthErr.Length, lengthErr.MaxLength)
+	}
+}
@@ -0,0 +1,91 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// DefaultMaxLength is the number of bytes a string or raw value may hold
+	// when read from a reader that does not set its own limit
+	DefaultMaxLength = 1 << 20
+
+	// chunkSize is the number of bytes a long value grows by while it is
+	// read, so that memory is only taken for data that actually arrived
+	chunkSize = 64 * 1024
+)
+
+// LengthError is returned when a string or raw value is longer than the
+// reader allows
+type LengthError struct {
+	Length    int
+	MaxLength int
+}
+
+func (err *LengthError) Error() string {
+	return fmt.Sprintf("codec: value of %d bytes is longer than the limit of %d bytes", err.Length, err.MaxLength)
+}
+
+// Limited is implemented by readers that set their own limit on the length
+// of the strings and raw values read from them
+type Limited interface {
+	MaxLength() int
+}
+
+// limitedReader is a reader limiting the length of values to maxLength
+type limitedReader struct {
+	io.Reader
+	maxLength int
+}
+
+// LimitReader returns a reader reading from reader, which limits the strings
+// and raw values read from it to maxLength bytes
+func LimitReader(reader io.Reader, maxLength int) io.Reader {
+	return &limitedReader{Reader: reader, maxLength: maxLength}
+}
+
+// ReadByte reads a single byte from the underlying reader
+func (reader *limitedReader) ReadByte() (byte, error) {
+	return ReadByte(reader.Reader)
+}
+
+// MaxLength returns the number of bytes a value read from the reader may hold
+func (reader *limitedReader) MaxLength() int {
+	return reader.maxLength
+}
+
+// maxLength returns the number of bytes a value read from the reader may hold
+func maxLength(reader io.Reader) int {
+	if limited, ok := reader.(Limited); ok {
+		return limited.MaxLength()
+	}
+	return DefaultMaxLength
+}
+
+// readBytes reads a value of length bytes. Long values are read a chunk at a
+// time, so a length that is not followed by the data can not take up memory.
+func readBytes(reader io.Reader, length int) ([]byte, error) {
+	if limit := maxLength(reader); length > limit {
+		return nil, &LengthError{Length: length, MaxLength: limit}
+	}
+	if length <= chunkSize {
+		data := make([]byte, length)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	data := make([]byte, 0, chunkSize)
+	for len(data) < length {
+		read := length - len(data)
+		if read > chunkSize {
+			read = chunkSize
+		}
+		start := len(data)
+		data = append(data, make([]byte, read)...)
+		if _, err := io.ReadFull(reader, data[start:]); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
@@ -7,5 +7,12 @@ type IEntry interface {
 	SetValue(interface{})
 	CompressToBytes() []byte
 	GetID() uint16
+	GetRawID() [2]byte
+	GetSequence() uint16
+	SetSequence(uint16)
+	GetFlags() byte
+	SetFlags(byte)
+	IsPersistent() bool
+	SetPersistent(bool)
 	GetType() EntryType
 }
@@ -2,9 +2,12 @@ package entry
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 
+	"github.com/techplexengineer/frc-networktables-go/codec"
 	"github.com/techplexengineer/frc-networktables-go/util"
 )
 
@@ -50,9 +53,11 @@ const (
 	flagTemporary byte = 0x00
 	flagPersist   byte = 0x01
 	flagReserved  byte = 0xFE
+)
 
-	boolFalse byte = 0x00
-	boolTrue  byte = 0x01
+const (
+	// FlagPersistent is the flag bit marking an entry the server must save across restarts
+	FlagPersistent = flagPersist
 )
 
 var (
@@ -60,6 +65,11 @@ var (
 	idSent = [2]byte{0xFF, 0xFF}
 )
 
+// IsPending returns whether the entry is still waiting for the server to assign it an ID
+func IsPending(e IEntry) bool {
+	return e.GetID() == binary.LittleEndian.Uint16(idSent[:])
+}
+
 // Base is the base struct for entries.
 type Base struct {
 	eName  string
@@ -72,81 +82,197 @@ type Base struct {
 
 // BuildFromReader creates an entry using the reader passed in
 func BuildFromReader(reader io.Reader) (IEntry, error) {
-	nameLen, _ := util.ReadULeb128(reader)
-	nameData := make([]byte, nameLen)
-	_, nameErr := io.ReadFull(reader, nameData[:])
+	name, nameErr := codec.ReadString(reader)
 	if nameErr != nil {
 		return nil, nameErr
 	}
-	name := string(nameData[:])
-	var typeData [1]byte
-	_, typeErr := io.ReadFull(reader, typeData[:])
-	if typeErr != nil {
-		return nil, typeErr
-	}
-	var idData [2]byte
-	_, idErr := io.ReadFull(reader, idData[:])
-	if idErr != nil {
-		return nil, idErr
-	}
-	var seqData [2]byte
-	_, seqErr := io.ReadFull(reader, seqData[:])
-	if seqErr != nil {
-		return nil, seqErr
+	header, err := readHeader(reader)
+	if err != nil {
+		return nil, err
 	}
-	var flagData [1]byte
-	_, flagErr := io.ReadFull(reader, flagData[:])
-	if flagErr != nil {
-		return nil, flagErr
-	}
-	_, _ = nameData, name
-	switch EntryType(typeData[0]) {
+	idData := [2]byte{header[1], header[2]}
+	seqData := [2]byte{header[3], header[4]}
+	flag := header[5]
+	switch EntryType(header[0]) {
 	case TypeBoolean:
-		return BooleanFromReader(name, idData, seqData, flagData[0], reader)
+		return BooleanFromReader(name, idData, seqData, flag, reader)
 	case TypeDouble:
-		return DoubleFromReader(name, idData, seqData, flagData[0], reader)
+		return DoubleFromReader(name, idData, seqData, flag, reader)
 	case TypeString:
-		return StringFromReader(name, idData, seqData, flagData[0], reader)
+		return StringFromReader(name, idData, seqData, flag, reader)
 	case TypeRaw:
-		return RawFromReader(name, idData, seqData, flagData[0], reader)
+		return RawFromReader(name, idData, seqData, flag, reader)
 	case TypeBooleanArr:
-		return BooleanArrFromReader(name, idData, seqData, flagData[0], reader)
+		return BooleanArrFromReader(name, idData, seqData, flag, reader)
 	case TypeDoubleArr:
-		return DoubleArrFromReader(name, idData, seqData, flagData[0], reader)
+		return DoubleArrFromReader(name, idData, seqData, flag, reader)
 	case TypeStringArr:
-		return StringArrFromReader(name, idData, seqData, flagData[0], reader)
+		return StringArrFromReader(name, idData, seqData, flag, reader)
+	case TypeRPCDef:
+		return RPCDefFromReader(name, idData, seqData, flag, reader)
 	default:
 		return nil, errors.New("entry: Unknown entry type")
 	}
 }
 
+// readHeader reads the type, ID, sequence number and flags of an entry. It is
+// read a byte at a time so that a buffered reader does not allocate.
+func readHeader(reader io.Reader) ([6]byte, error) {
+	var header [6]byte
+	for i := range header {
+		data, err := codec.ReadByte(reader)
+		if err == io.EOF {
+			// the name has been read, so the entry has been cut short
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return header, err
+		}
+		header[i] = data
+	}
+	return header, nil
+}
+
 // BuildFromBytes creates an entry using the data passed in.
 func BuildFromBytes(data []byte) (IEntry, error) {
-	nameLen, sizeLen := util.ReadULeb128(bytes.NewReader(data))
-	dName := string(data[sizeLen : nameLen-1])
-	dType := EntryType(data[nameLen])
-	dID := [2]byte{data[nameLen+1], data[nameLen+2]}
-	dSeq := [2]byte{data[nameLen+3], data[nameLen+4]}
-	dFlag := data[nameLen+5]
-	dValue := data[nameLen+6:]
+	reader := bytes.NewReader(data)
+	dName, err := codec.ReadString(reader)
+	if err != nil {
+		return nil, err
+	}
+	var header [6]byte
+	if _, err = io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+	dType := EntryType(header[0])
+	dID := [2]byte{header[1], header[2]}
+	dSeq := [2]byte{header[3], header[4]}
+	dFlag := header[5]
+	dValue := data[len(data)-reader.Len():]
 	switch dType {
 	case TypeBoolean:
-		return BooleanFromItems(dName, dID, dSeq, dFlag, dValue), nil
+		return BooleanFromItems(dName, dID, dSeq, dFlag, dValue)
+	case TypeDouble:
+		return DoubleFromItems(dName, dID, dSeq, dFlag, dValue)
+	case TypeString:
+		return StringFromItems(dName, dID, dSeq, dFlag, dValue)
+	case TypeRaw:
+		return RawFromItems(dName, dID, dSeq, dFlag, dValue)
+	case TypeBooleanArr:
+		return BooleanArrFromItems(dName, dID, dSeq, dFlag, dValue)
+	case TypeDoubleArr:
+		return DoubleArrFromItems(dName, dID, dSeq, dFlag, dValue)
+	case TypeStringArr:
+		return StringArrFromItems(dName, dID, dSeq, dFlag, dValue)
+	case TypeRPCDef:
+		return RPCDefFromItems(dName, dID, dSeq, dFlag, dValue)
+	default:
+		return nil, errors.New("entry: Unknown entry type")
+	}
+}
+
+// NewFromValue builds an entry of the given type that has not yet been assigned an ID by the server
+func NewFromValue(name string, entryType EntryType, value interface{}) (IEntry, error) {
+	return BuildFromValue(name, entryType, idSent, [2]byte{}, flagTemporary, value)
+}
+
+// BuildFromValue creates an entry of the given type from a go value.
+// An error is returned if the value's go type does not match the entry type,
+// or if an array value is too long to be sent.
+func BuildFromValue(name string, entryType EntryType, id [2]byte, sequence [2]byte, persist byte, value interface{}) (IEntry, error) {
+	var ok bool
+	var built IEntry
+	var err error
+	switch entryType {
+	case TypeBoolean:
+		var val bool
+		if val, ok = value.(bool); ok {
+			built = BooleanFromValue(name, id, sequence, persist, val)
+		}
 	case TypeDouble:
-		return DoubleFromItems(dName, dID, dSeq, dFlag, dValue), nil
+		var val float64
+		if val, ok = value.(float64); ok {
+			built = DoubleFromValue(name, id, sequence, persist, val)
+		}
 	case TypeString:
-		return StringFromItems(dName, dID, dSeq, dFlag, dValue), nil
+		var val string
+		if val, ok = value.(string); ok {
+			built = StringFromValue(name, id, sequence, persist, val)
+		}
 	case TypeRaw:
-		return RawFromItems(dName, dID, dSeq, dFlag, dValue), nil
+		var val []byte
+		if val, ok = value.([]byte); ok {
+			built = RawFromValue(name, id, sequence, persist, val)
+		}
 	case TypeBooleanArr:
-		return BooleanArrFromItems(dName, dID, dSeq, dFlag, dValue), nil
+		var val []bool
+		if val, ok = value.([]bool); ok {
+			built, err = BooleanArrFromValue(name, id, sequence, persist, val)
+		}
 	case TypeDoubleArr:
-		return DoubleArrFromItems(dName, dID, dSeq, dFlag, dValue), nil
+		var val []float64
+		if val, ok = value.([]float64); ok {
+			built, err = DoubleArrFromValue(name, id, sequence, persist, val)
+		}
 	case TypeStringArr:
-		return StringArrFromItems(dName, dID, dSeq, dFlag, dValue), nil
+		var val []string
+		if val, ok = value.([]string); ok {
+			built, err = StringArrFromValue(name, id, sequence, persist, val)
+		}
+	case TypeRPCDef:
+		var val RPCDefinition
+		if val, ok = value.(RPCDefinition); ok {
+			return RPCDefFromValue(name, id, sequence, persist, val)
+		}
 	default:
 		return nil, errors.New("entry: Unknown entry type")
 	}
+	if !ok {
+		return nil, fmt.Errorf("entry: %T is not a valid %s value", value, entryType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return built, nil
+}
+
+// GetRawID returns the ID of the entry as it is sent on the wire
+func (base *Base) GetRawID() [2]byte {
+	return base.eID
+}
+
+// GetSequence returns the sequence number of the entry
+func (base *Base) GetSequence() uint16 {
+	return binary.BigEndian.Uint16(base.eSeq[:])
+}
+
+// SetSequence changes the sequence number of the entry
+func (base *Base) SetSequence(sequence uint16) {
+	binary.BigEndian.PutUint16(base.eSeq[:], sequence)
+}
+
+// GetFlags returns the flags of the entry
+func (base *Base) GetFlags() byte {
+	return base.eFlag
+}
+
+// SetFlags changes the flags of the entry
+func (base *Base) SetFlags(flags byte) {
+	base.eFlag = flags
+}
+
+// IsPersistent returns whether or not the entry should persist beyond restarts.
+func (base *Base) IsPersistent() bool {
+	return base.eFlag&flagPersist == flagPersist
+}
+
+// SetPersistent sets or clears the persistent flag, leaving the other flags untouched
+func (base *Base) SetPersistent(persistent bool) {
+	if persistent {
+		base.eFlag |= flagPersist
+	} else {
+		base.eFlag &^= flagPersist
+	}
 }
 
 func (base *Base) clone() Base {
@@ -155,11 +281,9 @@ func (base *Base) clone() Base {
 
 // CompressToBytes remakes the original byte slice to represent this entry
 func (base *Base) compressToBytes() []byte {
-	var output []byte
-	nameBytes := []byte(base.eName)
-	nameLen := util.EncodeULeb128(uint32(len(nameBytes)))
-	output = append(output, nameLen...)
-	output = append(output, nameBytes...)
+	output := make([]byte, 0, 5+len(base.eName)+6+len(base.eValue))
+	output = util.AppendULeb128(output, uint32(len(base.eName)))
+	output = append(output, base.eName...)
 	output = append(output, base.eType.Byte())
 	output = append(output, base.eID[:]...)
 	output = append(output, base.eSeq[:]...)
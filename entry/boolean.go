@@ -3,32 +3,33 @@ package entry
 import (
 	"encoding/binary"
 	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
 // Boolean Entry
 type Boolean struct {
 	Base
-	trueValue    bool
-	isPersistent bool
+	trueValue bool
 }
 
 // BooleanFromReader builds a boolean entry using the provided parameters
 func BooleanFromReader(name string, id [2]byte, sequence [2]byte, persist byte, reader io.Reader) (*Boolean, error) {
-	var value [1]byte
-	_, err := io.ReadFull(reader, value[:])
+	val, err := codec.ReadBoolean(reader)
 	if err != nil {
 		return nil, err
 	}
-	return BooleanFromItems(name, id, sequence, persist, value[:]), nil
+	return BooleanFromValue(name, id, sequence, persist, val), nil
 }
 
 // BooleanFromItems builds a boolean entry using the provided parameters
-func BooleanFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) *Boolean {
-	val := (value[0] == boolTrue)
-	persistent := (persist == flagPersist)
+func BooleanFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) (*Boolean, error) {
+	val, err := codec.FromBytes(value, codec.ReadBoolean)
+	if err != nil {
+		return nil, err
+	}
 	return &Boolean{
-		trueValue:    val,
-		isPersistent: persistent,
+		trueValue: val,
 		Base: Base{
 			eName:  name,
 			eType:  TypeBoolean,
@@ -37,25 +38,39 @@ func BooleanFromItems(name string, id [2]byte, sequence [2]byte, persist byte, v
 			eFlag:  persist,
 			eValue: value,
 		},
+	}, nil
+}
+
+// BooleanFromValue builds a boolean entry from a go value
+func BooleanFromValue(name string, id [2]byte, sequence [2]byte, persist byte, value bool) *Boolean {
+	return &Boolean{
+		trueValue: value,
+		Base: Base{
+			eName:  name,
+			eType:  TypeBoolean,
+			eID:    id,
+			eSeq:   sequence,
+			eFlag:  persist,
+			eValue: codec.EncodeBoolean(value),
+		},
 	}
 }
 
+// NewBoolean builds a boolean entry that has not yet been assigned an ID by the server
+func NewBoolean(name string, value bool) *Boolean {
+	return BooleanFromValue(name, idSent, [2]byte{}, flagTemporary, value)
+}
+
 // GetValue returns the value of the Boolean
 func (o *Boolean) GetValue() interface{} {
 	return o.trueValue
 }
 
-// IsPersistent returns whether or not the entry should persist beyond restarts.
-func (o *Boolean) IsPersistent() bool {
-	return o.isPersistent
-}
-
 // Clone returns an identical entry
 func (o *Boolean) Clone() *Boolean {
 	return &Boolean{
-		trueValue:    o.trueValue,
-		isPersistent: o.isPersistent,
-		Base:         o.Base.clone(),
+		trueValue: o.trueValue,
+		Base:      o.Base.clone(),
 	}
 }
 
@@ -76,6 +91,12 @@ func (Boolean) GetType() EntryType {
 	return TypeBoolean
 }
 
+// SetValue changes the value of the Boolean. Values that are not a bool are ignored.
 func (o *Boolean) SetValue(newValue interface{}) {
-
+	value, ok := newValue.(bool)
+	if !ok {
+		return
+	}
+	o.trueValue = value
+	o.eValue = codec.EncodeBoolean(value)
 }
@@ -3,43 +3,33 @@ package entry
 import (
 	"encoding/binary"
 	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
 // BooleanArr Entry
 type BooleanArr struct {
 	Base
-	trueValue    []bool
-	isPersistent bool
+	trueValue []bool
 }
 
 // BooleanArrFromReader builds a BooleanArr entry using the provided parameters
 func BooleanArrFromReader(name string, id [2]byte, sequence [2]byte, persist byte, reader io.Reader) (*BooleanArr, error) {
-	var tempValSize [1]byte
-	_, sizeErr := io.ReadFull(reader, tempValSize[:])
-	if sizeErr != nil {
-		return nil, sizeErr
-	}
-	valSize := int(tempValSize[0])
-	value := make([]byte, valSize)
-	_, valErr := io.ReadFull(reader, value[:])
-	if valErr != nil {
-		return nil, valErr
+	val, err := codec.ReadBooleanArray(reader)
+	if err != nil {
+		return nil, err
 	}
-	return BooleanArrFromItems(name, id, sequence, persist, value), nil
+	return BooleanArrFromValue(name, id, sequence, persist, val)
 }
 
 // BooleanArrFromItems builds a BooleanArr entry using the provided parameters
-func BooleanArrFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) *BooleanArr {
-	valSize := int(value[0])
-	var val []bool
-	for counter := 1; counter-1 < valSize; counter++ {
-		tempVal := (value[counter] == boolTrue)
-		val = append(val, tempVal)
+func BooleanArrFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) (*BooleanArr, error) {
+	val, err := codec.FromBytes(value, codec.ReadBooleanArray)
+	if err != nil {
+		return nil, err
 	}
-	persistant := (persist == flagPersist)
 	return &BooleanArr{
-		trueValue:    val,
-		isPersistent: persistant,
+		trueValue: val,
 		Base: Base{
 			eName:  name,
 			eType:  TypeBooleanArr,
@@ -48,7 +38,32 @@ func BooleanArrFromItems(name string, id [2]byte, sequence [2]byte, persist byte
 			eFlag:  persist,
 			eValue: value,
 		},
+	}, nil
+}
+
+// BooleanArrFromValue builds a BooleanArr entry from a go value, which may hold at
+// most codec.MaxArrayLength elements
+func BooleanArrFromValue(name string, id [2]byte, sequence [2]byte, persist byte, value []bool) (*BooleanArr, error) {
+	encoded, err := codec.EncodeBooleanArray(value)
+	if err != nil {
+		return nil, err
 	}
+	return &BooleanArr{
+		trueValue: value,
+		Base: Base{
+			eName:  name,
+			eType:  TypeBooleanArr,
+			eID:    id,
+			eSeq:   sequence,
+			eFlag:  persist,
+			eValue: encoded,
+		},
+	}, nil
+}
+
+// NewBooleanArr builds a BooleanArr entry that has not yet been assigned an ID by the server
+func NewBooleanArr(name string, value []bool) (*BooleanArr, error) {
+	return BooleanArrFromValue(name, idSent, [2]byte{}, flagTemporary, value)
 }
 
 // GetValue returns the trueValue
@@ -61,17 +76,11 @@ func (o *BooleanArr) GetValueAtIndex(index int) bool {
 	return o.trueValue[index]
 }
 
-// IsPersistent returns whether or not the entry should persist beyond restarts.
-func (o *BooleanArr) IsPersistent() bool {
-	return o.isPersistent
-}
-
 // Clone returns an identical entry
 func (o *BooleanArr) Clone() *BooleanArr {
 	return &BooleanArr{
-		trueValue:    o.trueValue,
-		isPersistent: o.isPersistent,
-		Base:         o.Base.clone(),
+		trueValue: o.trueValue,
+		Base:      o.Base.clone(),
 	}
 }
 
@@ -88,6 +97,18 @@ func (o BooleanArr) GetID() uint16 {
 func (BooleanArr) GetType() EntryType {
 	return TypeBooleanArr
 }
-func (o *BooleanArr) SetValue(newValue interface{}) {
 
+// SetValue changes the value of the BooleanArr. Values that are not a []bool, or
+// that hold more than codec.MaxArrayLength elements, are ignored.
+func (o *BooleanArr) SetValue(newValue interface{}) {
+	value, ok := newValue.([]bool)
+	if !ok {
+		return
+	}
+	encoded, err := codec.EncodeBooleanArray(value)
+	if err != nil {
+		return
+	}
+	o.trueValue = value
+	o.eValue = encoded
 }
@@ -4,33 +4,32 @@ import (
 	"encoding/binary"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
 // Double Entry
 type Double struct {
 	Base
-	trueValue    float64
-	isPersistent bool
+	trueValue float64
 }
 
 // DoubleFromReader builds a double entry using the provided parameters
 func DoubleFromReader(name string, id [2]byte, sequence [2]byte, persist byte, reader io.Reader) (*Double, error) {
-	var value [8]byte
-	_, err := io.ReadFull(reader, value[:])
+	val, err := codec.ReadDouble(reader)
 	if err != nil {
 		return nil, err
 	}
-	return DoubleFromItems(name, id, sequence, persist, value[:]), nil
+	return DoubleFromValue(name, id, sequence, persist, val), nil
 }
 
 // DoubleFromItems builds a double entry using the provided parameters
-func DoubleFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) *Double {
-	val := util.BytesToFloat64(value[:8])
-	persistent := (persist == flagPersist)
+func DoubleFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) (*Double, error) {
+	val, err := codec.FromBytes(value, codec.ReadDouble)
+	if err != nil {
+		return nil, err
+	}
 	return &Double{
-		trueValue:    val,
-		isPersistent: persistent,
+		trueValue: val,
 		Base: Base{
 			eName:  name,
 			eType:  TypeDouble,
@@ -39,25 +38,39 @@ func DoubleFromItems(name string, id [2]byte, sequence [2]byte, persist byte, va
 			eFlag:  persist,
 			eValue: value,
 		},
+	}, nil
+}
+
+// DoubleFromValue builds a double entry from a go value
+func DoubleFromValue(name string, id [2]byte, sequence [2]byte, persist byte, value float64) *Double {
+	return &Double{
+		trueValue: value,
+		Base: Base{
+			eName:  name,
+			eType:  TypeDouble,
+			eID:    id,
+			eSeq:   sequence,
+			eFlag:  persist,
+			eValue: codec.EncodeDouble(value),
+		},
 	}
 }
 
+// NewDouble builds a double entry that has not yet been assigned an ID by the server
+func NewDouble(name string, value float64) *Double {
+	return DoubleFromValue(name, idSent, [2]byte{}, flagTemporary, value)
+}
+
 // GetValue returns the value of the Double
 func (o *Double) GetValue() interface{} {
 	return o.trueValue
 }
 
-// IsPersistent returns whether or not the entry should persist beyond restarts.
-func (o *Double) IsPersistent() bool {
-	return o.isPersistent
-}
-
 // Clone returns an identical entry
 func (o *Double) Clone() *Double {
 	return &Double{
-		trueValue:    o.trueValue,
-		isPersistent: o.isPersistent,
-		Base:         o.Base.clone(),
+		trueValue: o.trueValue,
+		Base:      o.Base.clone(),
 	}
 }
 
@@ -76,6 +89,12 @@ func (Double) GetType() EntryType {
 	return TypeDouble
 }
 
+// SetValue changes the value of the Double. Values that are not a float64 are ignored.
 func (o *Double) SetValue(newValue interface{}) {
-
+	value, ok := newValue.(float64)
+	if !ok {
+		return
+	}
+	o.trueValue = value
+	o.eValue = codec.EncodeDouble(value)
 }
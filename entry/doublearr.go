@@ -3,44 +3,33 @@ package entry
 import (
 	"encoding/binary"
 	"io"
-	//"github.com/HowardStark/abreuvoir/util"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
 // DoubleArr Entry
 type DoubleArr struct {
 	Base
-	trueValue    []float64
-	isPersistent bool
+	trueValue []float64
 }
 
 // DoubleArrFromReader builds a DoubleArr entry using the provided parameters
 func DoubleArrFromReader(name string, id [2]byte, sequence [2]byte, persist byte, reader io.Reader) (*DoubleArr, error) {
-	var tempValSize [1]byte
-	_, sizeErr := io.ReadFull(reader, tempValSize[:])
-	if sizeErr != nil {
-		return nil, sizeErr
-	}
-	valSize := int(tempValSize[0])
-	value := make([]byte, valSize*8)
-	_, valErr := io.ReadFull(reader, value[:])
-	if valErr != nil {
-		return nil, valErr
+	val, err := codec.ReadDoubleArray(reader)
+	if err != nil {
+		return nil, err
 	}
-	return DoubleArrFromItems(name, id, sequence, persist, value), nil
+	return DoubleArrFromValue(name, id, sequence, persist, val)
 }
 
 // DoubleArrFromItems builds a DoubleArr entry using the provided parameters
-func DoubleArrFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) *DoubleArr {
-	//valSize := int(value[0])
-	var val []float64
-	//for counter := 0; (counter)/8 < valSize; counter += 8 {
-	//	tempVal := util.BytesToFloat64(value[counter : counter+8])
-	//	val = append(val, tempVal)
-	//}
-	persistant := (persist == flagPersist)
+func DoubleArrFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) (*DoubleArr, error) {
+	val, err := codec.FromBytes(value, codec.ReadDoubleArray)
+	if err != nil {
+		return nil, err
+	}
 	return &DoubleArr{
-		trueValue:    val,
-		isPersistent: persistant,
+		trueValue: val,
 		Base: Base{
 			eName:  name,
 			eType:  TypeDoubleArr,
@@ -49,7 +38,32 @@ func DoubleArrFromItems(name string, id [2]byte, sequence [2]byte, persist byte,
 			eFlag:  persist,
 			eValue: value,
 		},
+	}, nil
+}
+
+// DoubleArrFromValue builds a DoubleArr entry from a go value, which may hold at
+// most codec.MaxArrayLength elements
+func DoubleArrFromValue(name string, id [2]byte, sequence [2]byte, persist byte, value []float64) (*DoubleArr, error) {
+	encoded, err := codec.EncodeDoubleArray(value)
+	if err != nil {
+		return nil, err
 	}
+	return &DoubleArr{
+		trueValue: value,
+		Base: Base{
+			eName:  name,
+			eType:  TypeDoubleArr,
+			eID:    id,
+			eSeq:   sequence,
+			eFlag:  persist,
+			eValue: encoded,
+		},
+	}, nil
+}
+
+// NewDoubleArr builds a DoubleArr entry that has not yet been assigned an ID by the server
+func NewDoubleArr(name string, value []float64) (*DoubleArr, error) {
+	return DoubleArrFromValue(name, idSent, [2]byte{}, flagTemporary, value)
 }
 
 // GetValue returns the value of the DoubleArr
@@ -63,16 +77,17 @@ func (o *DoubleArr) GetValueAtIndex(index int) float64 {
 }
 
 // IsPersistant returns whether or not the entry should persist beyond restarts.
+//
+// Deprecated: use IsPersistent
 func (o *DoubleArr) IsPersistant() bool {
-	return o.isPersistent
+	return o.IsPersistent()
 }
 
 // Clone returns an identical entry
 func (o *DoubleArr) Clone() *DoubleArr {
 	return &DoubleArr{
-		trueValue:    o.trueValue,
-		isPersistent: o.isPersistent,
-		Base:         o.Base.clone(),
+		trueValue: o.trueValue,
+		Base:      o.Base.clone(),
 	}
 }
 
@@ -90,6 +105,18 @@ func (o DoubleArr) GetID() uint16 {
 func (DoubleArr) GetType() EntryType {
 	return TypeDoubleArr
 }
-func (o *DoubleArr) SetValue(newValue interface{}) {
 
+// SetValue changes the value of the DoubleArr. Values that are not a []float64, or
+// that hold more than codec.MaxArrayLength elements, are ignored.
+func (o *DoubleArr) SetValue(newValue interface{}) {
+	value, ok := newValue.([]float64)
+	if !ok {
+		return
+	}
+	encoded, err := codec.EncodeDoubleArray(value)
+	if err != nil {
+		return
+	}
+	o.trueValue = value
+	o.eValue = encoded
 }
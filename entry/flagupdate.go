@@ -2,9 +2,13 @@ package entry
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 )
 
+// flagUpdateLength is the number of bytes of a FlagUpdate: the ID and the flags
+const flagUpdateLength = 3
+
 // FlagUpdate entry is a partial entry containing only certain fields of an actual entry
 type FlagUpdate struct {
 	ID           [2]byte
@@ -24,7 +28,7 @@ func FlagUpdateFromReader(reader io.Reader) (*FlagUpdate, error) {
 	if flagErr != nil {
 		return nil, flagErr
 	}
-	dPersist := (dFlags[0] == flagPersist)
+	dPersist := (dFlags[0]&flagPersist == flagPersist)
 	return &FlagUpdate{
 		ID:           dID,
 		IsPersistent: dPersist,
@@ -32,21 +36,25 @@ func FlagUpdateFromReader(reader io.Reader) (*FlagUpdate, error) {
 	}, nil
 }
 
-// FlagUpdateFromBytes builds an FlagUpdate from a byte slice
-func FlagUpdateFromBytes(data []byte) *FlagUpdate {
+// FlagUpdateFromBytes builds an FlagUpdate from a byte slice, which must hold
+// exactly the ID and the flags
+func FlagUpdateFromBytes(data []byte) (*FlagUpdate, error) {
+	if len(data) != flagUpdateLength {
+		return nil, fmt.Errorf("entry: flag update of %d bytes, want %d", len(data), flagUpdateLength)
+	}
 	dID := [2]byte{data[0], data[1]}
 	dFlags := data[2]
-	dPersist := (dFlags == flagPersist)
+	dPersist := (dFlags&flagPersist == flagPersist)
 	return &FlagUpdate{
 		ID:           dID,
 		IsPersistent: dPersist,
 		flags:        dFlags,
-	}
+	}, nil
 }
 
 // FlagUpdateFromItems builds an FlagUpdate using the provided parameters
 func FlagUpdateFromItems(dID [2]byte, dFlags byte) *FlagUpdate {
-	dPersist := (dFlags == flagPersist)
+	dPersist := (dFlags&flagPersist == flagPersist)
 	return &FlagUpdate{
 		ID:           dID,
 		IsPersistent: dPersist,
@@ -62,6 +70,11 @@ func (o *FlagUpdate) CompressToBytes() []byte {
 	return compressed
 }
 
+// GetFlags returns the new flags carried by the FlagUpdate
+func (o FlagUpdate) GetFlags() byte {
+	return o.flags
+}
+
 //func (o FlagUpdate) GetName() string {
 //	return o.Base.eName
 //}
@@ -0,0 +1,18 @@
+package entry
+
+import "testing"
+
+func TestFlagUpdateFromBytes(t *testing.T) {
+	update, err := FlagUpdateFromBytes([]byte{0x00, 0x07, FlagPersistent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.ID != [2]byte{0x00, 0x07} || update.GetFlags() != FlagPersistent || !update.IsPersistent {
+		t.Fatalf("decoded %+v", update)
+	}
+	for _, data := range [][]byte{nil, {0x00}, {0x00, 0x07}, {0x00, 0x07, 0x01, 0x00}} {
+		if _, err := FlagUpdateFromBytes(data); err == nil {
+			t.Errorf("FlagUpdateFromBytes accepted % x", data)
+		}
+	}
+}
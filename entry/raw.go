@@ -1,33 +1,35 @@
 package entry
 
 import (
-	"bytes"
 	"encoding/binary"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
 // Raw entry
 type Raw struct {
 	Base
-	trueValue    []byte
-	isPersistent bool
+	trueValue []byte
 }
 
 // RawFromReader builds a raw entry using the provided parameters
 func RawFromReader(name string, id [2]byte, sequence [2]byte, persist byte, reader io.Reader) (*Raw, error) {
-	valLen, sizeData := util.PeekULeb128(reader)
-	valData := make([]byte, valLen)
-	_, err := io.ReadFull(reader, valData[:])
+	val, err := codec.ReadRaw(reader)
+	if err != nil {
+		return nil, err
+	}
+	return RawFromValue(name, id, sequence, persist, val), nil
+}
+
+// RawFromItems builds a raw entry using the provided parameters
+func RawFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) (*Raw, error) {
+	val, err := codec.FromBytes(value, codec.ReadRaw)
 	if err != nil {
 		return nil, err
 	}
-	persistent := (persist == flagPersist)
-	value := append(sizeData, valData[:]...)
 	return &Raw{
-		trueValue:    valData[:],
-		isPersistent: persistent,
+		trueValue: val,
 		Base: Base{
 			eName:  name,
 			eType:  TypeRaw,
@@ -39,41 +41,36 @@ func RawFromReader(name string, id [2]byte, sequence [2]byte, persist byte, read
 	}, nil
 }
 
-// RawFromItems builds a raw entry using the provided parameters
-func RawFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) *Raw {
-	valLen, sizeLen := util.ReadULeb128(bytes.NewReader(value))
-	val := value[sizeLen : valLen-1]
-	persistent := (persist == flagPersist)
+// RawFromValue builds a raw entry from a go value
+func RawFromValue(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) *Raw {
 	return &Raw{
-		trueValue:    val,
-		isPersistent: persistent,
+		trueValue: value,
 		Base: Base{
 			eName:  name,
 			eType:  TypeRaw,
 			eID:    id,
 			eSeq:   sequence,
 			eFlag:  persist,
-			eValue: value,
+			eValue: codec.EncodeRaw(value),
 		},
 	}
 }
 
+// NewRaw builds a raw entry that has not yet been assigned an ID by the server
+func NewRaw(name string, value []byte) *Raw {
+	return RawFromValue(name, idSent, [2]byte{}, flagTemporary, value)
+}
+
 // GetValue returns the raw value of this entry
 func (o *Raw) GetValue() interface{} {
 	return o.trueValue
 }
 
-// IsPersistent returns whether or not the entry should persist beyond restarts.
-func (o *Raw) IsPersistent() bool {
-	return o.isPersistent
-}
-
 // Clone returns an identical entry
 func (o *Raw) Clone() *Raw {
 	return &Raw{
-		trueValue:    o.trueValue,
-		isPersistent: o.isPersistent,
-		Base:         o.Base.clone(),
+		trueValue: o.trueValue,
+		Base:      o.Base.clone(),
 	}
 }
 
@@ -91,6 +88,13 @@ func (o Raw) GetID() uint16 {
 func (Raw) GetType() EntryType {
 	return TypeRaw
 }
-func (o *Raw) SetValue(newValue interface{}) {
 
+// SetValue changes the value of the Raw. Values that are not a []byte are ignored.
+func (o *Raw) SetValue(newValue interface{}) {
+	value, ok := newValue.([]byte)
+	if !ok {
+		return
+	}
+	o.trueValue = value
+	o.eValue = codec.EncodeRaw(value)
 }
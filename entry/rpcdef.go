@@ -0,0 +1,290 @@
+package entry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/util"
+)
+
+// rpcVersion is the only supported version of the RPC definition data
+const rpcVersion byte = 0x01
+
+// RPCParam describes a parameter of a remote procedure
+type RPCParam struct {
+	Type    EntryType
+	Name    string
+	Default interface{}
+}
+
+// RPCResult describes a result of a remote procedure
+type RPCResult struct {
+	Type EntryType
+	Name string
+}
+
+// RPCDefinition is the definition data of a remote procedure
+type RPCDefinition struct {
+	Name    string
+	Params  []RPCParam
+	Results []RPCResult
+}
+
+// RPCDef Entry
+type RPCDef struct {
+	Base
+	trueValue RPCDefinition
+}
+
+// RPCDefFromReader builds a RPCDef entry using the provided parameters
+func RPCDefFromReader(name string, id [2]byte, sequence [2]byte, persist byte, reader io.Reader) (*RPCDef, error) {
+	valData, err := codec.ReadRaw(reader)
+	if err != nil {
+		return nil, err
+	}
+	return RPCDefFromItems(name, id, sequence, persist, codec.EncodeRaw(valData))
+}
+
+// RPCDefFromItems builds a RPCDef entry using the provided parameters
+func RPCDefFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) (*RPCDef, error) {
+	reader := bytes.NewReader(value)
+	valLen, _, err := util.ReadULeb128(reader)
+	if err != nil {
+		return nil, err
+	}
+	if int(valLen) != reader.Len() {
+		return nil, errors.New("entry: RPC definition length does not match its data")
+	}
+	val, err := decodeRPCDefinition(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCDef{
+		trueValue: val,
+		Base: Base{
+			eName:  name,
+			eType:  TypeRPCDef,
+			eID:    id,
+			eSeq:   sequence,
+			eFlag:  persist,
+			eValue: value,
+		},
+	}, nil
+}
+
+// RPCDefFromValue builds a RPCDef entry from a go value
+func RPCDefFromValue(name string, id [2]byte, sequence [2]byte, persist byte, value RPCDefinition) (*RPCDef, error) {
+	encoded, err := encodeRPCDefinition(value)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCDef{
+		trueValue: value,
+		Base: Base{
+			eName:  name,
+			eType:  TypeRPCDef,
+			eID:    id,
+			eSeq:   sequence,
+			eFlag:  persist,
+			eValue: encoded,
+		},
+	}, nil
+}
+
+// NewRPCDef builds a RPCDef entry that has not yet been assigned an ID by the server
+func NewRPCDef(name string, value RPCDefinition) (*RPCDef, error) {
+	return RPCDefFromValue(name, idSent, [2]byte{}, flagTemporary, value)
+}
+
+func decodeRPCDefinition(reader *bytes.Reader) (RPCDefinition, error) {
+	var def RPCDefinition
+	version, err := reader.ReadByte()
+	if err != nil {
+		return def, err
+	}
+	if version != rpcVersion {
+		return def, fmt.Errorf("entry: Unsupported RPC definition version %d", version)
+	}
+	if def.Name, err = codec.ReadString(reader); err != nil {
+		return def, err
+	}
+	paramCount, err := reader.ReadByte()
+	if err != nil {
+		return def, err
+	}
+	for counter := 0; counter < int(paramCount); counter++ {
+		var param RPCParam
+		paramType, err := reader.ReadByte()
+		if err != nil {
+			return def, err
+		}
+		param.Type = EntryType(paramType)
+		if param.Name, err = codec.ReadString(reader); err != nil {
+			return def, err
+		}
+		if param.Default, err = readValue(param.Type, reader); err != nil {
+			return def, err
+		}
+		def.Params = append(def.Params, param)
+	}
+	resultCount, err := reader.ReadByte()
+	if err != nil {
+		return def, err
+	}
+	for counter := 0; counter < int(resultCount); counter++ {
+		var result RPCResult
+		resultType, err := reader.ReadByte()
+		if err != nil {
+			return def, err
+		}
+		result.Type = EntryType(resultType)
+		if result.Name, err = codec.ReadString(reader); err != nil {
+			return def, err
+		}
+		def.Results = append(def.Results, result)
+	}
+	return def, nil
+}
+
+func encodeRPCDefinition(def RPCDefinition) ([]byte, error) {
+	data := []byte{rpcVersion}
+	data = append(data, codec.EncodeString(def.Name)...)
+	data = append(data, byte(len(def.Params)))
+	for _, param := range def.Params {
+		data = append(data, param.Type.Byte())
+		data = append(data, codec.EncodeString(param.Name)...)
+		defaultValue, err := encodeValue(param.Type, param.Default)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, defaultValue...)
+	}
+	data = append(data, byte(len(def.Results)))
+	for _, result := range def.Results {
+		data = append(data, result.Type.Byte())
+		data = append(data, codec.EncodeString(result.Name)...)
+	}
+	return append(util.EncodeULeb128(uint32(len(data))), data...), nil
+}
+
+// EncodeParams encodes the parameter values of a call to the procedure.
+// A nil value is replaced by the parameter's default value.
+func (def RPCDefinition) EncodeParams(values []interface{}) ([]byte, error) {
+	if len(values) != len(def.Params) {
+		return nil, fmt.Errorf("entry: %s takes %d parameters, got %d", def.Name, len(def.Params), len(values))
+	}
+	var data []byte
+	for index, param := range def.Params {
+		value := values[index]
+		if value == nil {
+			value = param.Default
+		}
+		encoded, err := encodeValue(param.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("entry: parameter %s: %s", param.Name, err)
+		}
+		data = append(data, encoded...)
+	}
+	return data, nil
+}
+
+// DecodeParams decodes the parameter values of a call to the procedure
+func (def RPCDefinition) DecodeParams(data []byte) ([]interface{}, error) {
+	types := make([]EntryType, len(def.Params))
+	for index, param := range def.Params {
+		types[index] = param.Type
+	}
+	return decodeValues(types, data)
+}
+
+// EncodeResults encodes the result values of a call to the procedure
+func (def RPCDefinition) EncodeResults(values []interface{}) ([]byte, error) {
+	if len(values) != len(def.Results) {
+		return nil, fmt.Errorf("entry: %s returns %d results, got %d", def.Name, len(def.Results), len(values))
+	}
+	var data []byte
+	for index, result := range def.Results {
+		encoded, err := encodeValue(result.Type, values[index])
+		if err != nil {
+			return nil, fmt.Errorf("entry: result %s: %s", result.Name, err)
+		}
+		data = append(data, encoded...)
+	}
+	return data, nil
+}
+
+// DecodeResults decodes the result values of a call to the procedure
+func (def RPCDefinition) DecodeResults(data []byte) ([]interface{}, error) {
+	types := make([]EntryType, len(def.Results))
+	for index, result := range def.Results {
+		types[index] = result.Type
+	}
+	return decodeValues(types, data)
+}
+
+func decodeValues(types []EntryType, data []byte) ([]interface{}, error) {
+	reader := bytes.NewReader(data)
+	values := make([]interface{}, len(types))
+	for index, valueType := range types {
+		value, err := readValue(valueType, reader)
+		if err != nil {
+			return nil, err
+		}
+		values[index] = value
+	}
+	if reader.Len() != 0 {
+		return nil, errors.New("entry: Unexpected data after RPC values")
+	}
+	return values, nil
+}
+
+// GetValue returns the definition of the remote procedure
+func (o *RPCDef) GetValue() interface{} {
+	return o.trueValue
+}
+
+// GetDefinition returns the definition of the remote procedure
+func (o *RPCDef) GetDefinition() RPCDefinition {
+	return o.trueValue
+}
+
+// Clone returns an identical entry
+func (o *RPCDef) Clone() *RPCDef {
+	return &RPCDef{
+		trueValue: o.trueValue,
+		Base:      o.Base.clone(),
+	}
+}
+
+// CompressToBytes returns a byte slice representing the RPCDef entry
+func (o *RPCDef) CompressToBytes() []byte {
+	return o.Base.compressToBytes()
+}
+
+func (o RPCDef) GetName() string {
+	return o.Base.eName
+}
+func (o RPCDef) GetID() uint16 {
+	return binary.LittleEndian.Uint16(o.eID[:])
+}
+func (RPCDef) GetType() EntryType {
+	return TypeRPCDef
+}
+
+// SetValue changes the definition of the RPCDef. Values that are not a RPCDefinition are ignored.
+func (o *RPCDef) SetValue(newValue interface{}) {
+	value, ok := newValue.(RPCDefinition)
+	if !ok {
+		return
+	}
+	encoded, err := encodeRPCDefinition(value)
+	if err != nil {
+		return
+	}
+	o.trueValue = value
+	o.eValue = encoded
+}
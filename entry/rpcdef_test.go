@@ -0,0 +1,142 @@
+package entry
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// everyTypeDefinition has a parameter and a result of every value type
+var everyTypeDefinition = RPCDefinition{
+	Name: "every type",
+	Params: []RPCParam{
+		{Type: TypeBoolean, Name: "boolean", Default: true},
+		{Type: TypeDouble, Name: "double", Default: 1.5},
+		{Type: TypeString, Name: "string", Default: "default"},
+		{Type: TypeRaw, Name: "raw", Default: []byte{0x00, 0xff}},
+		{Type: TypeBooleanArr, Name: "booleans", Default: []bool{true, false}},
+		{Type: TypeDoubleArr, Name: "doubles", Default: []float64{1, -2}},
+		{Type: TypeStringArr, Name: "strings", Default: []string{"a", ""}},
+	},
+	Results: []RPCResult{
+		{Type: TypeBoolean, Name: "ok"},
+		{Type: TypeDouble, Name: "sum"},
+		{Type: TypeStringArr, Name: "names"},
+	},
+}
+
+func TestRPCDefinitionRoundTrip(t *testing.T) {
+	tests := []RPCDefinition{
+		everyTypeDefinition,
+		{Name: "no parameters or results"},
+	}
+	for _, definition := range tests {
+		built, err := RPCDefFromValue("/rpc", [2]byte{0x00, 0x01}, [2]byte{0x00, 0x02}, 0x00, definition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := BuildFromBytes(built.CompressToBytes())
+		if err != nil {
+			t.Fatalf("%s: %s", definition.Name, err)
+		}
+		if decoded.GetType() != TypeRPCDef || decoded.GetName() != "/rpc" {
+			t.Fatalf("%s: decoded a %s named %s", definition.Name, decoded.GetType(), decoded.GetName())
+		}
+		if !reflect.DeepEqual(decoded.GetValue(), definition) {
+			t.Fatalf("decoded %#v, want %#v", decoded.GetValue(), definition)
+		}
+	}
+}
+
+func TestRPCDefinitionRejectsOtherVersions(t *testing.T) {
+	encoded, err := encodeRPCDefinition(everyTypeDefinition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the definition is short enough for its length to take a single byte
+	if encoded[1] != rpcVersion {
+		t.Fatalf("definition starts with version %d", encoded[1])
+	}
+	for _, version := range []byte{0x00, 0x02, 0xff} {
+		data := append([]byte{}, encoded...)
+		data[1] = version
+		if _, err := RPCDefFromItems("/rpc", [2]byte{}, [2]byte{}, 0x00, data); err == nil {
+			t.Errorf("version %d was accepted", version)
+		}
+	}
+}
+
+func TestRPCDefinitionRejectsTruncatedData(t *testing.T) {
+	encoded, err := encodeRPCDefinition(everyTypeDefinition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for length := 0; length < len(encoded); length++ {
+		if _, err := RPCDefFromItems("/rpc", [2]byte{}, [2]byte{}, 0x00, encoded[:length]); err == nil {
+			t.Fatalf("the first %d of %d bytes were accepted", length, len(encoded))
+		}
+	}
+}
+
+func TestRPCParamsRoundTrip(t *testing.T) {
+	// nil parameters are replaced by their defaults
+	data, err := everyTypeDefinition.EncodeParams(make([]interface{}, len(everyTypeDefinition.Params)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := everyTypeDefinition.DecodeParams(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, param := range everyTypeDefinition.Params {
+		if !reflect.DeepEqual(params[index], param.Default) {
+			t.Errorf("parameter %s decoded as %#v, want %#v", param.Name, params[index], param.Default)
+		}
+	}
+
+	given := []interface{}{false, 2.0, "given", []byte{}, []bool{}, []float64{3}, []string{"b"}}
+	if data, err = everyTypeDefinition.EncodeParams(given); err != nil {
+		t.Fatal(err)
+	}
+	if params, err = everyTypeDefinition.DecodeParams(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(params, given) {
+		t.Fatalf("decoded %#v, want %#v", params, given)
+	}
+
+	if _, err := everyTypeDefinition.EncodeParams([]interface{}{true}); err == nil {
+		t.Error("too few parameters were accepted")
+	}
+	wrongType := append([]interface{}{"not a boolean"}, given[1:]...)
+	if _, err := everyTypeDefinition.EncodeParams(wrongType); err == nil {
+		t.Error("a parameter of the wrong type was accepted")
+	}
+	if _, err := everyTypeDefinition.DecodeParams(data[:len(data)-1]); err == nil {
+		t.Error("truncated parameters were accepted")
+	}
+}
+
+func TestRPCResultsRoundTrip(t *testing.T) {
+	results := []interface{}{true, 4.5, []string{"x", "y"}}
+	data, err := everyTypeDefinition.EncodeResults(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := everyTypeDefinition.DecodeResults(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, results) {
+		t.Fatalf("decoded %#v, want %#v", decoded, results)
+	}
+	if _, err := everyTypeDefinition.EncodeResults(results[:2]); err == nil {
+		t.Error("too few results were accepted")
+	}
+	if _, err := everyTypeDefinition.DecodeResults(append(data, 0x00)); err == nil {
+		t.Error("results with trailing data were accepted")
+	}
+	if _, err := everyTypeDefinition.DecodeResults(bytes.Repeat([]byte{0x01}, 3)); err == nil {
+		t.Error("truncated results were accepted")
+	}
+}
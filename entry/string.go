@@ -1,34 +1,35 @@
 package entry
 
 import (
-	"bytes"
 	"encoding/binary"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
 // String Entry
 type String struct {
 	Base
-	trueValue    string
-	isPersistent bool
+	trueValue string
 }
 
 // StringFromReader builds a string entry using the provided parameters
 func StringFromReader(name string, id [2]byte, sequence [2]byte, persist byte, reader io.Reader) (*String, error) {
-	valLen, sizeData := util.PeekULeb128(reader)
-	valData := make([]byte, valLen)
-	_, err := io.ReadFull(reader, valData[:])
+	val, err := codec.ReadString(reader)
+	if err != nil {
+		return nil, err
+	}
+	return StringFromValue(name, id, sequence, persist, val), nil
+}
+
+// StringFromItems builds a string entry using the provided parameters
+func StringFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) (*String, error) {
+	val, err := codec.FromBytes(value, codec.ReadString)
 	if err != nil {
 		return nil, err
 	}
-	val := string(valData[:])
-	persistent := (persist == flagPersist)
-	value := append(sizeData, valData[:]...)
 	return &String{
-		trueValue:    val,
-		isPersistent: persistent,
+		trueValue: val,
 		Base: Base{
 			eName:  name,
 			eType:  TypeString,
@@ -40,41 +41,36 @@ func StringFromReader(name string, id [2]byte, sequence [2]byte, persist byte, r
 	}, nil
 }
 
-// StringFromItems builds a string entry using the provided parameters
-func StringFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) *String {
-	valLen, sizeLen := util.ReadULeb128(bytes.NewReader(value))
-	val := string(value[sizeLen : valLen-1])
-	persistent := (persist == flagPersist)
+// StringFromValue builds a string entry from a go value
+func StringFromValue(name string, id [2]byte, sequence [2]byte, persist byte, value string) *String {
 	return &String{
-		trueValue:    val,
-		isPersistent: persistent,
+		trueValue: value,
 		Base: Base{
 			eName:  name,
 			eType:  TypeString,
 			eID:    id,
 			eSeq:   sequence,
 			eFlag:  persist,
-			eValue: value,
+			eValue: codec.EncodeString(value),
 		},
 	}
 }
 
+// NewString builds a string entry that has not yet been assigned an ID by the server
+func NewString(name string, value string) *String {
+	return StringFromValue(name, idSent, [2]byte{}, flagTemporary, value)
+}
+
 // GetValue returns the value of the String
 func (o *String) GetValue() interface{} {
 	return o.trueValue
 }
 
-// IsPersistent returns whether or not the entry should persist beyond restarts.
-func (o *String) IsPersistent() bool {
-	return o.isPersistent
-}
-
 // Clone returns an identical entry
 func (o *String) Clone() *String {
 	return &String{
-		trueValue:    o.trueValue,
-		isPersistent: o.isPersistent,
-		Base:         o.Base.clone(),
+		trueValue: o.trueValue,
+		Base:      o.Base.clone(),
 	}
 }
 
@@ -92,6 +88,13 @@ func (o String) GetID() uint16 {
 func (String) GetType() EntryType {
 	return TypeString
 }
-func (o *String) SetValue(newValue interface{}) {
 
+// SetValue changes the value of the String. Values that are not a string are ignored.
+func (o *String) SetValue(newValue interface{}) {
+	value, ok := newValue.(string)
+	if !ok {
+		return
+	}
+	o.trueValue = value
+	o.eValue = codec.EncodeString(value)
 }
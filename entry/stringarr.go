@@ -1,46 +1,35 @@
 package entry
 
 import (
-	"bytes"
 	"encoding/binary"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
 // StringArr Entry
 type StringArr struct {
 	Base
-	trueValue    []string
-	isPersistent bool
+	trueValue []string
 }
 
 // StringArrFromReader builds a StringArr entry using the provided parameters
 func StringArrFromReader(name string, id [2]byte, sequence [2]byte, persist byte, reader io.Reader) (*StringArr, error) {
-	var value []byte
-	var tempValSize [1]byte
-	_, sizeErr := io.ReadFull(reader, tempValSize[:])
-	if sizeErr != nil {
-		return nil, sizeErr
+	val, err := codec.ReadStringArray(reader)
+	if err != nil {
+		return nil, err
 	}
-	value = append(value, tempValSize[0])
-	valSize := int(tempValSize[0])
-	var val []string
-	for counter := 0; counter < valSize; counter++ {
-		strLen, sizeData := util.PeekULeb128(reader)
-		value = append(value, sizeData...)
-		strData := make([]byte, strLen)
-		_, strErr := io.ReadFull(reader, strData[:])
-		if strErr != nil {
-			return nil, strErr
-		}
-		value = append(value, strData[:]...)
-		val = append(val, string(strData[:]))
+	return StringArrFromValue(name, id, sequence, persist, val)
+}
+
+// StringArrFromItems builds a StringArr entry using the provided parameters
+func StringArrFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) (*StringArr, error) {
+	val, err := codec.FromBytes(value, codec.ReadStringArray)
+	if err != nil {
+		return nil, err
 	}
-	persistent := (persist == flagPersist)
 	return &StringArr{
-		trueValue:    val,
-		isPersistent: persistent,
+		trueValue: val,
 		Base: Base{
 			eName:  name,
 			eType:  TypeStringArr,
@@ -52,32 +41,29 @@ func StringArrFromReader(name string, id [2]byte, sequence [2]byte, persist byte
 	}, nil
 }
 
-// StringArrFromItems builds a StringArr entry using the provided parameters
-func StringArrFromItems(name string, id [2]byte, sequence [2]byte, persist byte, value []byte) *StringArr {
-	valSize := int(value[0])
-	var val []string
-	var previousPos uint32 = 1
-	for counter := 0; counter < valSize; counter++ {
-		strPos, sizePos := util.ReadULeb128(bytes.NewReader(value[previousPos:]))
-		strPos += previousPos
-		sizePos += previousPos
-		tempVal := string(value[sizePos : strPos-1])
-		val = append(val, tempVal)
-		previousPos = strPos - 1
+// StringArrFromValue builds a StringArr entry from a go value, which may hold at
+// most codec.MaxArrayLength elements
+func StringArrFromValue(name string, id [2]byte, sequence [2]byte, persist byte, value []string) (*StringArr, error) {
+	encoded, err := codec.EncodeStringArray(value)
+	if err != nil {
+		return nil, err
 	}
-	persistent := (persist == flagPersist)
 	return &StringArr{
-		trueValue:    val,
-		isPersistent: persistent,
+		trueValue: value,
 		Base: Base{
 			eName:  name,
 			eType:  TypeStringArr,
 			eID:    id,
 			eSeq:   sequence,
 			eFlag:  persist,
-			eValue: value,
+			eValue: encoded,
 		},
-	}
+	}, nil
+}
+
+// NewStringArr builds a StringArr entry that has not yet been assigned an ID by the server
+func NewStringArr(name string, value []string) (*StringArr, error) {
+	return StringArrFromValue(name, idSent, [2]byte{}, flagTemporary, value)
 }
 
 // GetValue returns the value of the StringArr
@@ -90,17 +76,11 @@ func (o *StringArr) GetValueAtIndex(index int) string {
 	return o.trueValue[index]
 }
 
-// IsPersistent returns whether or not the entry should persist beyond restarts.
-func (o *StringArr) IsPersistent() bool {
-	return o.isPersistent
-}
-
 // Clone returns an identical entry
 func (o *StringArr) Clone() *StringArr {
 	return &StringArr{
-		trueValue:    o.trueValue,
-		isPersistent: o.isPersistent,
-		Base:         o.Base.clone(),
+		trueValue: o.trueValue,
+		Base:      o.Base.clone(),
 	}
 }
 
@@ -119,6 +99,17 @@ func (StringArr) GetType() EntryType {
 	return TypeStringArr
 }
 
+// SetValue changes the value of the StringArr. Values that are not a []string, or
+// that hold more than codec.MaxArrayLength elements, are ignored.
 func (o *StringArr) SetValue(newValue interface{}) {
-
+	value, ok := newValue.([]string)
+	if !ok {
+		return
+	}
+	encoded, err := codec.EncodeStringArray(value)
+	if err != nil {
+		return
+	}
+	o.trueValue = value
+	o.eValue = encoded
 }
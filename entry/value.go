@@ -0,0 +1,84 @@
+package entry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+)
+
+// readValue reads a single value of the given type, as used for RPC parameters and results
+func readValue(valueType EntryType, reader io.Reader) (interface{}, error) {
+	switch valueType {
+	case TypeBoolean:
+		return codec.ReadBoolean(reader)
+	case TypeDouble:
+		return codec.ReadDouble(reader)
+	case TypeString:
+		return codec.ReadString(reader)
+	case TypeRaw:
+		return codec.ReadRaw(reader)
+	case TypeBooleanArr:
+		return codec.ReadBooleanArray(reader)
+	case TypeDoubleArr:
+		return codec.ReadDoubleArray(reader)
+	case TypeStringArr:
+		return codec.ReadStringArray(reader)
+	default:
+		return nil, errors.New("entry: Unknown entry type")
+	}
+}
+
+// encodeValue encodes a single value of the given type, as used for RPC parameters and results
+func encodeValue(valueType EntryType, value interface{}) ([]byte, error) {
+	var ok bool
+	var encoded []byte
+	var err error
+	switch valueType {
+	case TypeBoolean:
+		var val bool
+		if val, ok = value.(bool); ok {
+			encoded = codec.EncodeBoolean(val)
+		}
+	case TypeDouble:
+		var val float64
+		if val, ok = value.(float64); ok {
+			encoded = codec.EncodeDouble(val)
+		}
+	case TypeString:
+		var val string
+		if val, ok = value.(string); ok {
+			encoded = codec.EncodeString(val)
+		}
+	case TypeRaw:
+		var val []byte
+		if val, ok = value.([]byte); ok {
+			encoded = codec.EncodeRaw(val)
+		}
+	case TypeBooleanArr:
+		var val []bool
+		if val, ok = value.([]bool); ok {
+			encoded, err = codec.EncodeBooleanArray(val)
+		}
+	case TypeDoubleArr:
+		var val []float64
+		if val, ok = value.([]float64); ok {
+			encoded, err = codec.EncodeDoubleArray(val)
+		}
+	case TypeStringArr:
+		var val []string
+		if val, ok = value.([]string); ok {
+			encoded, err = codec.EncodeStringArray(val)
+		}
+	default:
+		return nil, errors.New("entry: Unknown entry type")
+	}
+	if !ok {
+		return nil, fmt.Errorf("entry: %T is not a valid %s value", value, valueType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
@@ -7,5 +7,7 @@ type IEntryUpdate interface {
 	CompressToBytes() []byte
 	GetType() entry.EntryType //@todo change to entryType
 	GetID() uint16
+	GetRawID() [2]byte
+	GetSequence() uint16
 	GetValueUnsafe() interface{}
 }
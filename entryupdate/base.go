@@ -1,9 +1,12 @@
 package entryupdate
 
 import (
+	"encoding/binary"
 	"errors"
-	"github.com/techplexengineer/frc-networktables-go/entry"
 	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
 )
 
 const (
@@ -19,9 +22,6 @@ const (
 	flagTemporary byte = 0x00
 	flagPersist   byte = 0x01
 	flagReserved  byte = 0xFE
-
-	boolFalse byte = 0x00
-	boolTrue  byte = 0x01
 )
 
 // Base is the base struct for Entry updates
@@ -34,48 +34,104 @@ type Base struct {
 
 // BuildFromReader sleep
 func BuildFromReader(reader io.Reader) (IEntryUpdate, error) {
-	var idData [2]byte
-	_, idErr := io.ReadFull(reader, idData[:])
-	if idErr != nil {
-		return nil, idErr
+	header, err := readHeader(reader)
+	if err != nil {
+		return nil, err
 	}
-	var seqData [2]byte
-	_, seqErr := io.ReadFull(reader, seqData[:])
-	if seqErr != nil {
-		return nil, seqErr
+	idData := [2]byte{header[0], header[1]}
+	seqData := [2]byte{header[2], header[3]}
+	entryType := header[4]
+	switch entry.EntryType(entryType) {
+	case entry.TypeBoolean:
+		return BooleanFromReader(idData, seqData, entryType, reader)
+	case entry.TypeDouble:
+		return DoubleFromReader(idData, seqData, entryType, reader)
+	case entry.TypeString:
+		return StringFromReader(idData, seqData, entryType, reader)
+	case entry.TypeRaw:
+		return RawFromReader(idData, seqData, entryType, reader)
+	case entry.TypeBooleanArr:
+		return BooleanArrFromReader(idData, seqData, entryType, reader)
+	case entry.TypeDoubleArr:
+		return DoubleArrFromReader(idData, seqData, entryType, reader)
+	case entry.TypeStringArr:
+		return StringArrFromReader(idData, seqData, entryType, reader)
+	case entry.TypeRPCDef:
+		return RPCDefFromReader(idData, seqData, entryType, reader)
+	default:
+		return nil, errors.New("entry: Unknown entry type")
 	}
-	var typeData [1]byte
-	_, typeErr := io.ReadFull(reader, typeData[:])
-	if typeErr != nil {
-		return nil, typeErr
+}
+
+// readHeader reads the ID, sequence number and type of an update. It is read
+// a byte at a time so that a buffered reader does not allocate.
+func readHeader(reader io.Reader) ([5]byte, error) {
+	var header [5]byte
+	for i := range header {
+		data, err := codec.ReadByte(reader)
+		if err == io.EOF && i > 0 {
+			// running out part way through is reported like io.ReadFull does
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return header, err
+		}
+		header[i] = data
 	}
-	switch entry.EntryType(typeData[0]) {
+	return header, nil
+}
+
+// BuildFromEntry creates an update carrying the ID, sequence number and value of the entry passed in
+func BuildFromEntry(e entry.IEntry) (IEntryUpdate, error) {
+	id := e.GetRawID()
+	var seq [2]byte
+	binary.BigEndian.PutUint16(seq[:], e.GetSequence())
+	switch e.GetType() {
 	case entry.TypeBoolean:
-		return BooleanFromReader(idData, seqData, typeData[0], reader)
+		return BooleanFromValue(id, seq, e.GetValue().(bool)), nil
 	case entry.TypeDouble:
-		return DoubleFromReader(idData, seqData, typeData[0], reader)
+		return DoubleFromValue(id, seq, e.GetValue().(float64)), nil
 	case entry.TypeString:
-		return StringFromReader(idData, seqData, typeData[0], reader)
+		return StringFromValue(id, seq, e.GetValue().(string)), nil
 	case entry.TypeRaw:
-		return RawFromReader(idData, seqData, typeData[0], reader)
+		return RawFromValue(id, seq, e.GetValue().([]byte)), nil
 	case entry.TypeBooleanArr:
-		return BooleanArrFromReader(idData, seqData, typeData[0], reader)
+		return asUpdate(BooleanArrFromValue(id, seq, e.GetValue().([]bool)))
 	case entry.TypeDoubleArr:
-		return DoubleArrFromReader(idData, seqData, typeData[0], reader)
+		return asUpdate(DoubleArrFromValue(id, seq, e.GetValue().([]float64)))
 	case entry.TypeStringArr:
-		return StringArrFromReader(idData, seqData, typeData[0], reader)
+		return asUpdate(StringArrFromValue(id, seq, e.GetValue().([]string)))
 	default:
 		return nil, errors.New("entry: Unknown entry type")
 	}
 }
 
+// asUpdate returns an update built from a value as an IEntryUpdate, so that
+// a failed build is a nil interface rather than a nil pointer in one
+func asUpdate[T IEntryUpdate](update T, err error) (IEntryUpdate, error) {
+	if err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+// GetRawID returns the ID of the updated entry as it is sent on the wire
+func (base *Base) GetRawID() [2]byte {
+	return base.ID
+}
+
+// GetSequence returns the sequence number carried by the update
+func (base *Base) GetSequence() uint16 {
+	return binary.BigEndian.Uint16(base.Seq[:])
+}
+
 func (base *Base) clone() Base {
 	return *base
 }
 
 // CompressToBytes returns a byte slice representing the Update entry
 func (base *Base) compressToBytes() []byte {
-	var compressed []byte
+	compressed := make([]byte, 0, 5+len(base.Value))
 	compressed = append(compressed, base.ID[:]...)
 	compressed = append(compressed, base.Seq[:]...)
 	compressed = append(compressed, base.Type.Byte())
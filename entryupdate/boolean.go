@@ -2,8 +2,10 @@ package entryupdate
 
 import (
 	"encoding/binary"
-	"github.com/techplexengineer/frc-networktables-go/entry"
 	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
 )
 
 // Boolean Entry
@@ -14,17 +16,19 @@ type Boolean struct {
 
 // BooleanFromReader builds a boolean entry using the provided parameters
 func BooleanFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (*Boolean, error) {
-	var value [1]byte
-	_, err := io.ReadFull(reader, value[:])
+	val, err := codec.ReadBoolean(reader)
 	if err != nil {
 		return nil, err
 	}
-	return BooleanFromItems(id, sequence, etype, value[:]), nil
+	return BooleanFromValue(id, sequence, val), nil
 }
 
 // BooleanFromItems builds a boolean entry using the provided parameters
-func BooleanFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *Boolean {
-	val := (value[0] == boolTrue)
+func BooleanFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) (*Boolean, error) {
+	val, err := codec.FromBytes(value, codec.ReadBoolean)
+	if err != nil {
+		return nil, err
+	}
 	return &Boolean{
 		trueValue: val,
 		Base: Base{
@@ -33,6 +37,19 @@ func BooleanFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *B
 			Type:  entry.TypeBoolean,
 			Value: value,
 		},
+	}, nil
+}
+
+// BooleanFromValue builds a boolean entry update from a go value
+func BooleanFromValue(id [2]byte, sequence [2]byte, value bool) *Boolean {
+	return &Boolean{
+		trueValue: value,
+		Base: Base{
+			ID:    id,
+			Seq:   sequence,
+			Type:  entry.TypeBoolean,
+			Value: codec.EncodeBoolean(value),
+		},
 	}
 }
 
@@ -2,8 +2,10 @@ package entryupdate
 
 import (
 	"encoding/binary"
-	"github.com/techplexengineer/frc-networktables-go/entry"
 	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
 )
 
 // BooleanArr Entry
@@ -14,27 +16,18 @@ type BooleanArr struct {
 
 // BooleanArrFromReader builds a BooleanArr entry using the provided parameters
 func BooleanArrFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (*BooleanArr, error) {
-	var tempValSize [1]byte
-	_, sizeErr := io.ReadFull(reader, tempValSize[:])
-	if sizeErr != nil {
-		return nil, sizeErr
+	val, err := codec.ReadBooleanArray(reader)
+	if err != nil {
+		return nil, err
 	}
-	valSize := int(tempValSize[0])
-	value := make([]byte, valSize)
-	_, valErr := io.ReadFull(reader, value[:])
-	if valErr != nil {
-		return nil, valErr
-	}
-	return BooleanArrFromItems(id, sequence, etype, value), nil
+	return BooleanArrFromValue(id, sequence, val)
 }
 
 // BooleanArrFromItems builds a BooleanArr entry using the provided parameters
-func BooleanArrFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *BooleanArr {
-	valSize := int(value[0])
-	var val []bool
-	for counter := 1; counter-1 < valSize; counter++ {
-		tempVal := (value[counter] == boolTrue)
-		val = append(val, tempVal)
+func BooleanArrFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) (*BooleanArr, error) {
+	val, err := codec.FromBytes(value, codec.ReadBooleanArray)
+	if err != nil {
+		return nil, err
 	}
 	return &BooleanArr{
 		trueValue: val,
@@ -44,7 +37,25 @@ func BooleanArrFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte)
 			Type:  entry.TypeBooleanArr,
 			Value: value,
 		},
+	}, nil
+}
+
+// BooleanArrFromValue builds a BooleanArr entry update from a go value, which may
+// hold at most codec.MaxArrayLength elements
+func BooleanArrFromValue(id [2]byte, sequence [2]byte, value []bool) (*BooleanArr, error) {
+	encoded, err := codec.EncodeBooleanArray(value)
+	if err != nil {
+		return nil, err
 	}
+	return &BooleanArr{
+		trueValue: value,
+		Base: Base{
+			ID:    id,
+			Seq:   sequence,
+			Type:  entry.TypeBooleanArr,
+			Value: encoded,
+		},
+	}, nil
 }
 
 // GetValue returns the trueValue
@@ -75,7 +86,7 @@ func (booleanArr *BooleanArr) CompressToBytes() []byte {
 }
 
 func (BooleanArr) GetType() entry.EntryType {
-	return entry.TypeDoubleArr
+	return entry.TypeBooleanArr
 }
 func (o BooleanArr) GetID() uint16 {
 	return binary.LittleEndian.Uint16(o.ID[:])
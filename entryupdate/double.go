@@ -2,10 +2,10 @@ package entryupdate
 
 import (
 	"encoding/binary"
-	"github.com/techplexengineer/frc-networktables-go/entry"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
 )
 
 // Double Entry
@@ -16,17 +16,19 @@ type Double struct {
 
 // DoubleFromReader builds a double entry using the provided parameters
 func DoubleFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (*Double, error) {
-	var value [8]byte
-	_, err := io.ReadFull(reader, value[:])
+	val, err := codec.ReadDouble(reader)
 	if err != nil {
 		return nil, err
 	}
-	return DoubleFromItems(id, sequence, etype, value[:]), nil
+	return DoubleFromValue(id, sequence, val), nil
 }
 
 // DoubleFromItems builds a double entry using the provided parameters
-func DoubleFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *Double {
-	val := util.BytesToFloat64(value[:8])
+func DoubleFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) (*Double, error) {
+	val, err := codec.FromBytes(value, codec.ReadDouble)
+	if err != nil {
+		return nil, err
+	}
 	return &Double{
 		trueValue: val,
 		Base: Base{
@@ -35,6 +37,19 @@ func DoubleFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *Do
 			Type:  entry.TypeDouble,
 			Value: value,
 		},
+	}, nil
+}
+
+// DoubleFromValue builds a double entry update from a go value
+func DoubleFromValue(id [2]byte, sequence [2]byte, value float64) *Double {
+	return &Double{
+		trueValue: value,
+		Base: Base{
+			ID:    id,
+			Seq:   sequence,
+			Type:  entry.TypeDouble,
+			Value: codec.EncodeDouble(value),
+		},
 	}
 }
 
@@ -2,10 +2,10 @@ package entryupdate
 
 import (
 	"encoding/binary"
-	"github.com/techplexengineer/frc-networktables-go/entry"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
 )
 
 // DoubleArr Entry
@@ -16,27 +16,18 @@ type DoubleArr struct {
 
 // DoubleArrFromReader builds a DoubleArr entry using the provided parameters
 func DoubleArrFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (*DoubleArr, error) {
-	var tempValSize [1]byte
-	_, sizeErr := io.ReadFull(reader, tempValSize[:])
-	if sizeErr != nil {
-		return nil, sizeErr
-	}
-	valSize := int(tempValSize[0])
-	value := make([]byte, valSize*8)
-	_, valErr := io.ReadFull(reader, value[:])
-	if valErr != nil {
-		return nil, valErr
+	val, err := codec.ReadDoubleArray(reader)
+	if err != nil {
+		return nil, err
 	}
-	return DoubleArrFromItems(id, sequence, etype, value), nil
+	return DoubleArrFromValue(id, sequence, val)
 }
 
 // DoubleArrFromItems builds a DoubleArr entry using the provided parameters
-func DoubleArrFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *DoubleArr {
-	valSize := int(value[0])
-	var val []float64
-	for counter := 1; (counter-1)/8 < valSize; counter += 8 {
-		tempVal := util.BytesToFloat64(value[counter : counter+8])
-		val = append(val, tempVal)
+func DoubleArrFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) (*DoubleArr, error) {
+	val, err := codec.FromBytes(value, codec.ReadDoubleArray)
+	if err != nil {
+		return nil, err
 	}
 	return &DoubleArr{
 		trueValue: val,
@@ -46,7 +37,25 @@ func DoubleArrFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte)
 			Type:  entry.TypeDoubleArr,
 			Value: value,
 		},
+	}, nil
+}
+
+// DoubleArrFromValue builds a DoubleArr entry update from a go value, which may
+// hold at most codec.MaxArrayLength elements
+func DoubleArrFromValue(id [2]byte, sequence [2]byte, value []float64) (*DoubleArr, error) {
+	encoded, err := codec.EncodeDoubleArray(value)
+	if err != nil {
+		return nil, err
 	}
+	return &DoubleArr{
+		trueValue: value,
+		Base: Base{
+			ID:    id,
+			Seq:   sequence,
+			Type:  entry.TypeDoubleArr,
+			Value: encoded,
+		},
+	}, nil
 }
 
 // GetValue returns the value of the DoubleArr
@@ -1,12 +1,11 @@
 package entryupdate
 
 import (
-	"bytes"
 	"encoding/binary"
-	"github.com/techplexengineer/frc-networktables-go/entry"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
 )
 
 // Raw entry
@@ -17,15 +16,21 @@ type Raw struct {
 
 // RawFromReader builds a raw entry using the provided parameters
 func RawFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (*Raw, error) {
-	valLen, sizeData := util.PeekULeb128(reader)
-	valData := make([]byte, valLen)
-	_, err := io.ReadFull(reader, valData[:])
+	val, err := codec.ReadRaw(reader)
+	if err != nil {
+		return nil, err
+	}
+	return RawFromValue(id, sequence, val), nil
+}
+
+// RawFromItems builds a raw entry using the provided parameters
+func RawFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) (*Raw, error) {
+	val, err := codec.FromBytes(value, codec.ReadRaw)
 	if err != nil {
 		return nil, err
 	}
-	value := append(sizeData, valData[:]...)
 	return &Raw{
-		trueValue: valData[:],
+		trueValue: val,
 		Base: Base{
 			ID:    id,
 			Seq:   sequence,
@@ -35,17 +40,15 @@ func RawFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (
 	}, nil
 }
 
-// RawFromItems builds a raw entry using the provided parameters
-func RawFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *Raw {
-	valLen, sizeLen := util.ReadULeb128(bytes.NewReader(value))
-	val := value[sizeLen : valLen-1]
+// RawFromValue builds a raw entry update from a go value
+func RawFromValue(id [2]byte, sequence [2]byte, value []byte) *Raw {
 	return &Raw{
-		trueValue: val,
+		trueValue: value,
 		Base: Base{
 			ID:    id,
 			Seq:   sequence,
 			Type:  entry.TypeRaw,
-			Value: value,
+			Value: codec.EncodeRaw(value),
 		},
 	}
 }
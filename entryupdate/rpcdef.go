@@ -0,0 +1,70 @@
+package entryupdate
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+// RPCDef entry update
+type RPCDef struct {
+	Base
+	trueValue entry.RPCDefinition
+}
+
+// RPCDefFromReader builds a RPCDef entry update using the provided parameters
+func RPCDefFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (*RPCDef, error) {
+	valData, err := codec.ReadRaw(reader)
+	if err != nil {
+		return nil, err
+	}
+	return RPCDefFromItems(id, sequence, etype, codec.EncodeRaw(valData))
+}
+
+// RPCDefFromItems builds a RPCDef entry update using the provided parameters
+func RPCDefFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) (*RPCDef, error) {
+	def, err := entry.RPCDefFromItems("", id, sequence, flagTemporary, value)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCDef{
+		trueValue: def.GetDefinition(),
+		Base: Base{
+			ID:    id,
+			Seq:   sequence,
+			Type:  entry.TypeRPCDef,
+			Value: value,
+		},
+	}, nil
+}
+
+// GetValue returns the definition of the remote procedure
+func (rpcDef *RPCDef) GetValue() entry.RPCDefinition {
+	return rpcDef.trueValue
+}
+
+func (rpcDef *RPCDef) GetValueUnsafe() interface{} {
+	return rpcDef.trueValue
+}
+
+// Clone returns an identical entry update
+func (rpcDef *RPCDef) Clone() *RPCDef {
+	return &RPCDef{
+		trueValue: rpcDef.trueValue,
+		Base:      rpcDef.Base.clone(),
+	}
+}
+
+// CompressToBytes returns a byte slice representing the RPCDef entry update
+func (rpcDef *RPCDef) CompressToBytes() []byte {
+	return rpcDef.Base.compressToBytes()
+}
+
+func (RPCDef) GetType() entry.EntryType {
+	return entry.TypeRPCDef
+}
+func (o RPCDef) GetID() uint16 {
+	return binary.LittleEndian.Uint16(o.ID[:])
+}
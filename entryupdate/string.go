@@ -1,12 +1,11 @@
 package entryupdate
 
 import (
-	"bytes"
 	"encoding/binary"
-	"github.com/techplexengineer/frc-networktables-go/entry"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
 )
 
 // String Entry
@@ -17,14 +16,19 @@ type String struct {
 
 // StringFromReader builds a string entry using the provided parameters
 func StringFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (*String, error) {
-	valLen, sizeData := util.PeekULeb128(reader)
-	valData := make([]byte, valLen)
-	_, err := io.ReadFull(reader, valData[:])
+	val, err := codec.ReadString(reader)
+	if err != nil {
+		return nil, err
+	}
+	return StringFromValue(id, sequence, val), nil
+}
+
+// StringFromItems builds a string entry using the provided parameters
+func StringFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) (*String, error) {
+	val, err := codec.FromBytes(value, codec.ReadString)
 	if err != nil {
 		return nil, err
 	}
-	val := string(valData[:])
-	value := append(sizeData, valData[:]...)
 	return &String{
 		trueValue: val,
 		Base: Base{
@@ -36,17 +40,15 @@ func StringFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader
 	}, nil
 }
 
-// StringFromItems builds a string entry using the provided parameters
-func StringFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *String {
-	valLen, sizeLen := util.ReadULeb128(bytes.NewReader(value))
-	val := string(value[sizeLen : valLen-1])
+// StringFromValue builds a string entry update from a go value
+func StringFromValue(id [2]byte, sequence [2]byte, value string) *String {
 	return &String{
-		trueValue: val,
+		trueValue: value,
 		Base: Base{
 			ID:    id,
 			Seq:   sequence,
 			Type:  entry.TypeString,
-			Value: value,
+			Value: codec.EncodeString(value),
 		},
 	}
 }
@@ -1,12 +1,11 @@
 package entryupdate
 
 import (
-	"bytes"
 	"encoding/binary"
-	"github.com/techplexengineer/frc-networktables-go/entry"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
 )
 
 // StringArr Entry
@@ -18,25 +17,18 @@ type StringArr struct {
 
 // StringArrFromReader builds a StringArr entry using the provided parameters
 func StringArrFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Reader) (*StringArr, error) {
-	var value []byte
-	var tempValSize [1]byte
-	_, sizeErr := io.ReadFull(reader, tempValSize[:])
-	if sizeErr != nil {
-		return nil, sizeErr
+	val, err := codec.ReadStringArray(reader)
+	if err != nil {
+		return nil, err
 	}
-	value = append(value, tempValSize[0])
-	valSize := int(tempValSize[0])
-	var val []string
-	for counter := 0; counter < valSize; counter++ {
-		strLen, sizeData := util.PeekULeb128(reader)
-		value = append(value, sizeData...)
-		strData := make([]byte, strLen)
-		_, strErr := io.ReadFull(reader, strData[:])
-		if strErr != nil {
-			return nil, strErr
-		}
-		value = append(value, strData[:]...)
-		val = append(val, string(strData[:]))
+	return StringArrFromValue(id, sequence, val)
+}
+
+// StringArrFromItems builds a StringArr entry using the provided parameters
+func StringArrFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) (*StringArr, error) {
+	val, err := codec.FromBytes(value, codec.ReadStringArray)
+	if err != nil {
+		return nil, err
 	}
 	return &StringArr{
 		trueValue: val,
@@ -49,28 +41,22 @@ func StringArrFromReader(id [2]byte, sequence [2]byte, etype byte, reader io.Rea
 	}, nil
 }
 
-// StringArrFromItems builds a StringArr entry using the provided parameters
-func StringArrFromItems(id [2]byte, sequence [2]byte, etype byte, value []byte) *StringArr {
-	valSize := int(value[0])
-	var val []string
-	var previousPos uint32 = 1
-	for counter := 0; counter < valSize; counter++ {
-		strPos, sizePos := util.ReadULeb128(bytes.NewReader(value[previousPos:]))
-		strPos += previousPos
-		sizePos += previousPos
-		tempVal := string(value[sizePos : strPos-1])
-		val = append(val, tempVal)
-		previousPos = strPos - 1
+// StringArrFromValue builds a StringArr entry update from a go value, which may
+// hold at most codec.MaxArrayLength elements
+func StringArrFromValue(id [2]byte, sequence [2]byte, value []string) (*StringArr, error) {
+	encoded, err := codec.EncodeStringArray(value)
+	if err != nil {
+		return nil, err
 	}
 	return &StringArr{
-		trueValue: val,
+		trueValue: value,
 		Base: Base{
 			ID:    id,
 			Seq:   sequence,
 			Type:  entry.TypeStringArr,
-			Value: value,
+			Value: encoded,
 		},
-	}
+	}, nil
 }
 
 // GetValue returns the value of the StringArr
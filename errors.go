@@ -0,0 +1,28 @@
+package frcntgo
+
+import (
+	"fmt"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+// KeyNotFoundError is returned when there is no entry at the requested key
+type KeyNotFoundError struct {
+	Key string
+}
+
+func (err *KeyNotFoundError) Error() string {
+	return fmt.Sprintf("client: entry %s does not exist", err.Key)
+}
+
+// TypeMismatchError is returned when the entry at the requested key holds a
+// different type of value than the one asked for
+type TypeMismatchError struct {
+	Key      string
+	Expected entry.EntryType
+	Actual   entry.EntryType
+}
+
+func (err *TypeMismatchError) Error() string {
+	return fmt.Sprintf("client: entry %s is a %s, not a %s", err.Key, err.Actual, err.Expected)
+}
@@ -1,3 +1,3 @@
 module github.com/techplexengineer/frc-networktables-go
 
-go 1.16
+go 1.18
@@ -0,0 +1,242 @@
+package frcntgo
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/util"
+)
+
+// EntryEvent is a bit mask of the changes to an entry a listener is told about
+type EntryEvent int
+
+const (
+	// EventCreated is sent when an entry is created
+	EventCreated EntryEvent = 1 << iota
+	// EventUpdated is sent when the value of an entry changes
+	EventUpdated
+	// EventDeleted is sent when an entry is deleted
+	EventDeleted
+	// EventFlagsChanged is sent when the flags of an entry change
+	EventFlagsChanged
+	// EventLocal is set on changes made by this client. Listeners only
+	// receive them when registered with it.
+	EventLocal
+	// EventImmediate makes a new listener receive EventCreated for every
+	// matching entry that already exists, and is set on those notifications
+	EventImmediate
+
+	// EventAll is every change made to an entry, by this client or others
+	EventAll = EventCreated | EventUpdated | EventDeleted | EventFlagsChanged | EventLocal
+)
+
+// EntryNotification describes a change to an entry
+type EntryNotification struct {
+	Key   string
+	Type  entry.EntryType
+	Value interface{}
+	Flags byte
+	Event EntryEvent
+}
+
+// EntryListener is called with every change to the entries it listens to
+type EntryListener func(notification EntryNotification)
+
+// ConnectionListener is called whenever the status of the client changes
+type ConnectionListener func(status ClientStatus)
+
+// Listener is a registered listener, which is kept to unregister it later
+type Listener struct {
+	client *Client
+	id     int
+}
+
+// entryListener holds the entries a listener is interested in
+type entryListener struct {
+	key      string
+	prefix   bool
+	events   EntryEvent
+	callback EntryListener
+}
+
+func (l *entryListener) matches(key string, event EntryEvent) bool {
+	if event&EventLocal != 0 && l.events&EventLocal == 0 {
+		return false
+	}
+	if event&l.events&^EventLocal == 0 {
+		return false
+	}
+	if l.prefix {
+		return strings.HasPrefix(key, l.key)
+	}
+	return key == l.key
+}
+
+// AddKeyListener calls the listener with the chosen events for the entry at
+// the specified key
+func (c *Client) AddKeyListener(key string, events EntryEvent, listener EntryListener) *Listener {
+	return c.addEntryListener(&entryListener{
+		key:      util.SanitizeKey(key),
+		events:   events,
+		callback: listener,
+	})
+}
+
+// AddPrefixListener calls the listener with the chosen events for every entry
+// whose key starts with the prefix. An empty prefix matches every entry.
+func (c *Client) AddPrefixListener(prefix string, events EntryEvent, listener EntryListener) *Listener {
+	return c.addEntryListener(&entryListener{
+		key:      prefix,
+		prefix:   true,
+		events:   events,
+		callback: listener,
+	})
+}
+
+// AddConnectionListener calls the listener whenever the status of the client
+// changes. If immediate is set it is also called with the current status.
+func (c *Client) AddConnectionListener(listener ConnectionListener, immediate bool) *Listener {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.listenerMutex.Lock()
+	defer c.listenerMutex.Unlock()
+	c.nextListener++
+	c.connectionListeners[c.nextListener] = listener
+	if immediate {
+		status := c.status
+		c.queueNotification(func() { listener(status) })
+	}
+	return &Listener{client: c, id: c.nextListener}
+}
+
+func (c *Client) addEntryListener(listener *entryListener) *Listener {
+	c.listenerMutex.Lock()
+	defer c.listenerMutex.Unlock()
+	c.nextListener++
+	c.entryListeners[c.nextListener] = listener
+	if listener.events&EventImmediate != 0 {
+		for name, e := range c.entries.all() {
+			if listener.matches(name, EventCreated) {
+				notification := newNotification(e, EventCreated|EventImmediate)
+				c.queueNotification(func() { listener.callback(notification) })
+			}
+		}
+	}
+	return &Listener{client: c, id: c.nextListener}
+}
+
+// Unregister stops the listener from being called. Notifications already
+// waiting to be delivered may still arrive.
+func (l *Listener) Unregister() {
+	l.client.listenerMutex.Lock()
+	defer l.client.listenerMutex.Unlock()
+	delete(l.client.entryListeners, l.id)
+	delete(l.client.connectionListeners, l.id)
+}
+
+func newNotification(e entry.IEntry, event EntryEvent) EntryNotification {
+	return EntryNotification{
+		Key:   e.GetName(),
+		Type:  e.GetType(),
+		Value: e.GetValue(),
+		Flags: e.GetFlags(),
+		Event: event,
+	}
+}
+
+// entryChanges returns the events that turned the previous entry into the
+// current one. Either of them is nil when the entry was created or deleted.
+func entryChanges(previous, current entry.IEntry) EntryEvent {
+	switch {
+	case previous == nil && current == nil:
+		return 0
+	case previous == nil:
+		return EventCreated
+	case current == nil:
+		return EventDeleted
+	}
+	var event EntryEvent
+	if previous.GetType() != current.GetType() || !reflect.DeepEqual(previous.GetValue(), current.GetValue()) {
+		event |= EventUpdated
+	}
+	if previous.GetFlags() != current.GetFlags() {
+		event |= EventFlagsChanged
+	}
+	return event
+}
+
+// notifyEntry tells the listeners of the entry about the change. The entry
+// is the deleted one for EventDeleted.
+func (c *Client) notifyEntry(e entry.IEntry, event EntryEvent) {
+	if e == nil || event&^EventLocal == 0 {
+		return
+	}
+	notification := newNotification(e, event)
+	c.listenerMutex.Lock()
+	defer c.listenerMutex.Unlock()
+	for _, listener := range c.entryListeners {
+		if listener.matches(notification.Key, event) {
+			callback := listener.callback
+			c.queueNotification(func() { callback(notification) })
+		}
+	}
+}
+
+// setStatus changes the status of the client and tells the connection
+// listeners. The caller must hold the client's mutex.
+func (c *Client) setStatus(status ClientStatus) {
+	if c.status == status {
+		return
+	}
+	c.status = status
+	close(c.statusChanged)
+	c.statusChanged = make(chan struct{})
+	c.listenerMutex.Lock()
+	defer c.listenerMutex.Unlock()
+	for _, listener := range c.connectionListeners {
+		callback := listener
+		c.queueNotification(func() { callback(status) })
+	}
+}
+
+// queueNotification hands a call to a listener to the notification
+// goroutine, so listeners never run while the client is handling a message.
+// The queue is unbounded so it never blocks while the client holds its mutex.
+func (c *Client) queueNotification(notify func()) {
+	c.notifyMutex.Lock()
+	c.notifications = append(c.notifications, notify)
+	c.notifyMutex.Unlock()
+	select {
+	case c.notifyReady <- struct{}{}:
+	default:
+	}
+}
+
+// takeNotifications removes and returns every queued notification
+func (c *Client) takeNotifications() []func() {
+	c.notifyMutex.Lock()
+	defer c.notifyMutex.Unlock()
+	notifications := c.notifications
+	c.notifications = nil
+	return notifications
+}
+
+// dispatchNotifications calls the listeners in the order the changes
+// happened, should be called as a gofunc
+func (c *Client) dispatchNotifications() {
+	for {
+		select {
+		case <-c.done:
+			// deliver what was queued before the client closed
+			for _, notify := range c.takeNotifications() {
+				notify()
+			}
+			return
+		case <-c.notifyReady:
+			for _, notify := range c.takeNotifications() {
+				notify()
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package frcntgo
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+// recorder keeps the notifications a listener received
+type recorder struct {
+	mutex         sync.Mutex
+	notifications []EntryNotification
+}
+
+func (r *recorder) listen(notification EntryNotification) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.notifications = append(r.notifications, notification)
+}
+
+// received returns the key and event of every notification so far
+func (r *recorder) received() []EntryNotification {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	received := make([]EntryNotification, len(r.notifications))
+	for index, notification := range r.notifications {
+		received[index] = EntryNotification{Key: notification.Key, Event: notification.Event}
+	}
+	return received
+}
+
+// flushNotifications waits until every notification queued so far has been
+// delivered. Listeners are called in order, so it changes an entry of its own
+// and waits for that change to be delivered.
+func flushNotifications(t *testing.T, client *Client) {
+	t.Helper()
+	delivered := make(chan struct{})
+	listener := client.AddKeyListener("/flush", EventCreated|EventLocal, func(EntryNotification) { close(delivered) })
+	defer listener.Unregister()
+	client.Delete("/flush")
+	if err := client.PutBoolean("/flush", true); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notifications were never delivered")
+	}
+}
+
+func TestListenerLocalChanges(t *testing.T) {
+	server, port := startServer(t)
+	client := startClient(t, port)
+	remote := &recorder{}
+	local := &recorder{}
+	defer client.AddKeyListener("/speed", EventCreated|EventUpdated, remote.listen).Unregister()
+	defer client.AddKeyListener("/speed", EventCreated|EventUpdated|EventLocal, local.listen).Unregister()
+
+	if err := client.PutDouble("/speed", 1); err != nil {
+		t.Fatal(err)
+	}
+	flushNotifications(t, client)
+	if received := remote.received(); len(received) != 0 {
+		t.Fatalf("listener without EventLocal got %v", received)
+	}
+	want := []EntryNotification{{Key: "/speed", Event: EventCreated | EventLocal}}
+	if received := local.received(); !reflect.DeepEqual(received, want) {
+		t.Fatalf("listener with EventLocal got %v, want %v", received, want)
+	}
+
+	// changes made by others reach both, without EventLocal
+	eventually(t, func() bool { return server.GetEntry("/speed") != nil }, "the entry never reached the server")
+	server.SetValue("/speed", entry.TypeDouble, 2.0)
+	eventually(t, func() bool {
+		value, _ := client.GetDouble("/speed")
+		return value == 2
+	}, "the update never reached the client")
+	flushNotifications(t, client)
+	want = []EntryNotification{{Key: "/speed", Event: EventUpdated}}
+	if received := remote.received(); !reflect.DeepEqual(received, want) {
+		t.Fatalf("listener without EventLocal got %v, want %v", received, want)
+	}
+	if received := local.received(); !reflect.DeepEqual(received[1:], want) {
+		t.Fatalf("listener with EventLocal got %v, want %v last", received, want)
+	}
+}
+
+func TestListenerImmediate(t *testing.T) {
+	server, port := startServer(t)
+	server.SetValue("/drive/speed", entry.TypeDouble, 1.0)
+	server.SetValue("/arm/angle", entry.TypeDouble, 2.0)
+	client := startClient(t, port)
+
+	immediate := &recorder{}
+	later := &recorder{}
+	defer client.AddPrefixListener("/drive/", EventCreated|EventImmediate, immediate.listen).Unregister()
+	defer client.AddPrefixListener("/drive/", EventCreated, later.listen).Unregister()
+	flushNotifications(t, client)
+	want := []EntryNotification{{Key: "/drive/speed", Event: EventCreated | EventImmediate}}
+	if received := immediate.received(); !reflect.DeepEqual(received, want) {
+		t.Fatalf("listener with EventImmediate got %v, want %v", received, want)
+	}
+	if received := later.received(); len(received) != 0 {
+		t.Fatalf("listener without EventImmediate got %v", received)
+	}
+}
+
+func TestListenerKeyAndPrefix(t *testing.T) {
+	server, port := startServer(t)
+	client := startClient(t, port)
+	key := &recorder{}
+	prefix := &recorder{}
+	everything := &recorder{}
+	defer client.AddKeyListener("drive/speed/", EventCreated, key.listen).Unregister()
+	defer client.AddPrefixListener("/drive/", EventCreated, prefix.listen).Unregister()
+	defer client.AddPrefixListener("", EventCreated, everything.listen).Unregister()
+
+	keys := []string{"/drive/speed", "/drive/speedy", "/drive/arm/angle", "/driver", "/other"}
+	for _, name := range keys {
+		server.SetValue(name, entry.TypeDouble, 1.0)
+	}
+	eventually(t, func() bool { return client.ContainsKey("/other") }, "the entries never reached the client")
+	flushNotifications(t, client)
+
+	created := func(names ...string) []EntryNotification {
+		notifications := []EntryNotification{}
+		for _, name := range names {
+			notifications = append(notifications, EntryNotification{Key: name, Event: EventCreated})
+		}
+		return notifications
+	}
+	if received := key.received(); !reflect.DeepEqual(received, created("/drive/speed")) {
+		t.Errorf("key listener got %v", received)
+	}
+	if received := prefix.received(); !reflect.DeepEqual(received, created("/drive/speed", "/drive/speedy", "/drive/arm/angle")) {
+		t.Errorf("prefix listener got %v", received)
+	}
+	// the empty prefix matches every entry, but not flushNotifications' local one
+	if received := everything.received(); len(received) != len(keys) {
+		t.Errorf("listener of every entry got %v", received)
+	}
+}
+
+func TestListenerUnregister(t *testing.T) {
+	_, port := startServer(t)
+	client := startClient(t, port)
+	received := &recorder{}
+	listener := client.AddKeyListener("/speed", EventAll, received.listen)
+	client.PutDouble("/speed", 1)
+	flushNotifications(t, client)
+	listener.Unregister()
+	client.PutDouble("/speed", 2)
+	client.Delete("/speed")
+	flushNotifications(t, client)
+	want := []EntryNotification{{Key: "/speed", Event: EventCreated | EventLocal}}
+	if got := received.received(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("listener got %v, want %v", got, want)
+	}
+	// unregistering twice is harmless
+	listener.Unregister()
+}
+
+func TestConnectionListener(t *testing.T) {
+	server, port := startServer(t)
+	client := startClient(t, port)
+
+	var mutex sync.Mutex
+	var immediate, later []ClientStatus
+	defer client.AddConnectionListener(func(status ClientStatus) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		immediate = append(immediate, status)
+	}, true).Unregister()
+	unregistered := client.AddConnectionListener(func(status ClientStatus) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		later = append(later, status)
+	}, false)
+	flushNotifications(t, client)
+	mutex.Lock()
+	if !reflect.DeepEqual(immediate, []ClientStatus{ClientInSync}) || len(later) != 0 {
+		t.Fatalf("listeners got %v and %v, want only the current status", immediate, later)
+	}
+	mutex.Unlock()
+	unregistered.Unregister()
+
+	server.Close()
+	eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return immediate[len(immediate)-1] == ClientDisconnected
+	}, "the listener was never told the connection was lost")
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(later) != 0 {
+		t.Fatalf("unregistered listener got %v", later)
+	}
+}
@@ -0,0 +1,78 @@
+package frcntgo
+
+import (
+	"fmt"
+	"log"
+)
+
+// Logger receives the client's and server's log messages. Traces of every
+// message sent and received are logged at debug level, changes to the
+// connection at info level and protocol errors at warn or error level.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// LogLevel is the least severe level a logger made by NewLogger writes
+type LogLevel int
+
+const (
+	// LevelDebug writes every message, including traces of the wire protocol
+	LevelDebug LogLevel = iota
+	// LevelInfo writes changes to the connection and anything more severe
+	LevelInfo
+	// LevelWarn writes protocol errors that are recovered from and errors
+	LevelWarn
+	// LevelError only writes errors
+	LevelError
+	// LevelNone writes nothing
+	LevelNone
+)
+
+// levelLogger writes the messages at or above its level to a standard logger
+type levelLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+// NewLogger returns a Logger writing messages at or above the level to the
+// standard logger. A nil logger writes to the log package's standard logger.
+func NewLogger(logger *log.Logger, level LogLevel) Logger {
+	return &levelLogger{logger: logger, level: level}
+}
+
+// defaultLogger is used unless another logger is given. It leaves out the
+// wire traces, which are far too frequent for most programs.
+func defaultLogger() Logger {
+	return NewLogger(nil, LevelInfo)
+}
+
+func (l *levelLogger) logf(level LogLevel, prefix string, format string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+	line := prefix + fmt.Sprintf(format, args...)
+	if l.logger == nil {
+		log.Output(3, line)
+		return
+	}
+	l.logger.Output(3, line)
+}
+
+func (l *levelLogger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, "DEBUG ", format, args)
+}
+
+func (l *levelLogger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, "INFO ", format, args)
+}
+
+func (l *levelLogger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, "WARN ", format, args)
+}
+
+func (l *levelLogger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, "ERROR ", format, args)
+}
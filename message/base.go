@@ -70,6 +70,9 @@ const (
 
 	lsbFirstConnect byte = 0x00
 	lsbReconnect    byte = 0x01
+
+	// clearAllMagic must follow a Clear All Entries message to avoid accidental misinterpretation
+	clearAllMagic uint32 = 0xD06CB27A
 )
 
 // Base is the base struct for Messages
@@ -103,15 +106,14 @@ func BuildFromReader(messageType MessageType, reader io.Reader) (IMessage, error
 	case TypeEntryDelete:
 		return EntryDeleteFromReader(reader)
 	case TypeClearAllEntries:
-		//fallthrough
+		return ClearAllEntriesFromReader(reader)
 	case TypeRPCExec:
-		//fallthrough
+		return RPCExecFromReader(reader)
 	case TypeRPCResponse:
-		//fallthrough
+		return RPCResponseFromReader(reader)
 	default:
 		return nil, errors.New("message: Unknown message type")
 	}
-	return nil, errors.New("message: Unknown message type")
 }
 
 func (m MessageType) Byte() byte {
@@ -120,8 +122,11 @@ func (m MessageType) Byte() byte {
 
 // compressToBytes remakes the original byte slice to represent this entry
 func (base *Base) compressToBytes() []byte {
-	output := []byte{}
+	return base.appendTo(make([]byte, 0, 1+len(base.mData)))
+}
+
+// appendTo appends the message in its byte array form to the output
+func (base *Base) appendTo(output []byte) []byte {
 	output = append(output, base.mType.Byte())
-	output = append(output, base.mData...)
-	return output
+	return append(output, base.mData...)
 }
@@ -0,0 +1,53 @@
+package message
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ClearAllEntries message
+type ClearAllEntries struct {
+	Base
+}
+
+// ClearAllEntriesFromReader builds a new ClearAllEntries message using the provided reader.
+// An error is returned if the magic value does not match the one required by the spec.
+func ClearAllEntriesFromReader(reader io.Reader) (*ClearAllEntries, error) {
+	var magic [4]byte
+	_, err := io.ReadFull(reader, magic[:])
+	if err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(magic[:]) != clearAllMagic {
+		return nil, errors.New("message: Invalid Clear All Entries magic value")
+	}
+	return &ClearAllEntries{
+		Base: Base{
+			mType: TypeClearAllEntries,
+			mData: magic[:],
+		},
+	}, nil
+}
+
+// ClearAllEntriesFromItems builds a new ClearAllEntries message
+func ClearAllEntriesFromItems() *ClearAllEntries {
+	var magic [4]byte
+	binary.BigEndian.PutUint32(magic[:], clearAllMagic)
+	return &ClearAllEntries{
+		Base: Base{
+			mType: TypeClearAllEntries,
+			mData: magic[:],
+		},
+	}
+}
+
+// CompressToBytes returns the message in its byte array form
+func (clearAllEntries *ClearAllEntries) CompressToBytes() []byte {
+	return clearAllEntries.Base.compressToBytes()
+}
+
+// GetType returns the message's type
+func (clearAllEntries *ClearAllEntries) GetType() MessageType {
+	return TypeClearAllEntries
+}
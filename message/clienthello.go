@@ -1,12 +1,15 @@
 package message
 
 import (
-	"bytes"
+	"encoding/binary"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
+// identityRev is the first protocol revision whose Client Hello carries the client's identity
+const identityRev = 0x0300
+
 // ClientHello message
 type ClientHello struct {
 	Base
@@ -14,24 +17,29 @@ type ClientHello struct {
 	identity string
 }
 
-// ClientHelloFromReader builds a new ClientHello message using the provided io.Reader
+// ClientHelloFromReader builds a new ClientHello message using the provided io.Reader.
+// Only clients speaking NetworkTables 3.0 or later send their identity, so it
+// is left empty for older ones.
 func ClientHelloFromReader(reader io.Reader) (IMessage, error) {
 	var protocolRev [2]byte
 	_, err := io.ReadFull(reader, protocolRev[:])
 	if err != nil {
 		return nil, err
 	}
-	nameLen, sizeData := util.PeekULeb128(reader)
-	nameData := make([]byte, nameLen)
-	_, err = io.ReadFull(reader, nameData[:])
+	if binary.BigEndian.Uint16(protocolRev[:]) < identityRev {
+		return &ClientHello{
+			protoRev: protocolRev,
+			Base: Base{
+				mType: TypeClientHello,
+				mData: protocolRev[:],
+			},
+		}, nil
+	}
+	name, err := codec.ReadString(reader)
 	if err != nil {
 		return nil, err
 	}
-	name := string(nameData[:])
-	var totalData []byte
-	totalData = append(totalData, protocolRev[:]...)
-	totalData = append(totalData, sizeData...)
-	totalData = append(totalData, nameData[:]...)
+	totalData := append(protocolRev[:], codec.EncodeString(name)...)
 	return &ClientHello{
 		identity: name,
 		protoRev: protocolRev,
@@ -42,10 +50,13 @@ func ClientHelloFromReader(reader io.Reader) (IMessage, error) {
 	}, nil
 }
 
-// ClientHelloFromItems builds a new ClientHello message using the provided parameters
-func ClientHelloFromItems(protocolRev [2]byte, nameData []byte) *ClientHello {
-	nameLen, sizeLen := util.ReadULeb128(bytes.NewBuffer(nameData))
-	name := string(nameData[sizeLen:nameLen])
+// ClientHelloFromItems builds a new ClientHello message using the provided parameters.
+// An error is returned if the name data is not a single encoded string.
+func ClientHelloFromItems(protocolRev [2]byte, nameData []byte) (*ClientHello, error) {
+	name, err := codec.FromBytes(nameData, codec.ReadString)
+	if err != nil {
+		return nil, err
+	}
 	var totalData []byte
 	totalData = append(totalData, protocolRev[:]...)
 	totalData = append(totalData, nameData[:]...)
@@ -56,7 +67,7 @@ func ClientHelloFromItems(protocolRev [2]byte, nameData []byte) *ClientHello {
 			mType: TypeClientHello,
 			mData: totalData,
 		},
-	}
+	}, nil
 }
 
 // GetProtoRev returns the client's NetworkTable protocol revision
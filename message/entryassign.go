@@ -20,9 +20,9 @@ func EntryAssignFromReader(reader io.Reader) (*EntryAssign, error) {
 	}
 	return &EntryAssign{
 		entry: tempEntry,
+		// the byte array form is only made if the message is sent on
 		Base: Base{
 			mType: TypeEntryAssign,
-			mData: tempEntry.CompressToBytes(),
 		},
 	}, nil
 }
@@ -60,7 +60,16 @@ func (entryAssign *EntryAssign) GetEntry() entry.IEntry {
 
 // CompressToBytes returns the message in its byte array form
 func (entryAssign *EntryAssign) CompressToBytes() []byte {
-	return entryAssign.Base.compressToBytes()
+	return entryAssign.appendTo(nil)
+}
+
+// appendTo appends the message in its byte array form to the output
+func (entryAssign *EntryAssign) appendTo(output []byte) []byte {
+	if entryAssign.mData == nil {
+		output = append(output, entryAssign.mType.Byte())
+		return append(output, entryAssign.entry.CompressToBytes()...)
+	}
+	return entryAssign.Base.appendTo(output)
 }
 
 // GetType returns the message's type
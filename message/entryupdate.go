@@ -20,9 +20,9 @@ func EntryUpdateFromReader(reader io.Reader) (*EntryUpdate, error) {
 	}
 	return &EntryUpdate{
 		Update: tempUpdate,
+		// the byte array form is only made if the message is sent on
 		Base: Base{
 			mType: TypeEntryUpdate,
-			mData: tempUpdate.CompressToBytes(),
 		},
 	}, nil
 }
@@ -45,7 +45,16 @@ func (entryUpdate *EntryUpdate) GetUpdate() entryupdate.IEntryUpdate {
 
 // CompressToBytes returns the message in its byte array form
 func (entryUpdate *EntryUpdate) CompressToBytes() []byte {
-	return entryUpdate.Base.compressToBytes()
+	return entryUpdate.appendTo(nil)
+}
+
+// appendTo appends the message in its byte array form to the output
+func (entryUpdate *EntryUpdate) appendTo(output []byte) []byte {
+	if entryUpdate.mData == nil {
+		output = append(output, entryUpdate.mType.Byte())
+		return append(output, entryUpdate.Update.CompressToBytes()...)
+	}
+	return entryUpdate.Base.appendTo(output)
 }
 
 // GetType returns the message's type
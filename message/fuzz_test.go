@@ -0,0 +1,116 @@
+package message
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/entryupdate"
+)
+
+// fuzzSeeds returns the byte form of every message type, along with streams
+// that are cut short or claim values longer than they hold
+func fuzzSeeds(f *testing.F) [][]byte {
+	f.Helper()
+	id := [2]byte{0x00, 0x01}
+	sequence := [2]byte{0x00, 0x02}
+	clientHello, err := ClientHelloFromItems([2]byte{0x03, 0x00}, codec.EncodeString("fuzz client"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	serverHello, err := ServerHelloFromItems(0x01, codec.EncodeString("fuzz server"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	definition, err := entry.RPCDefFromValue("/rpc", id, sequence, 0x00, entry.RPCDefinition{
+		Name:    "add",
+		Params:  []entry.RPCParam{{Type: entry.TypeDouble, Name: "a", Default: 1.0}},
+		Results: []entry.RPCResult{{Type: entry.TypeDouble, Name: "sum"}},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+	entries := []entry.IEntry{
+		entry.BooleanFromValue("/boolean", id, sequence, 0x01, true),
+		entry.DoubleFromValue("/double", id, sequence, 0x00, 1.5),
+		entry.StringFromValue("/string", id, sequence, 0x00, "value"),
+		entry.RawFromValue("/raw", id, sequence, 0x00, []byte{0x01, 0x02}),
+		definition,
+	}
+	updates := []entryupdate.IEntryUpdate{
+		entryupdate.BooleanFromValue(id, sequence, true),
+		entryupdate.DoubleFromValue(id, sequence, 1.5),
+		entryupdate.StringFromValue(id, sequence, "value"),
+		entryupdate.RawFromValue(id, sequence, []byte{0x01, 0x02}),
+	}
+	messages := []IMessage{
+		KeepAliveFromItems(),
+		clientHello,
+		ProtoUnsupportedFromItems([2]byte{0x03, 0x00}),
+		ServerHelloCompleteFromItems(),
+		serverHello,
+		ClientHelloCompleteFromItems(),
+		EntryFlagUpdateFromItems(id, 0x01),
+		EntryDeleteFromItems(id),
+		ClearAllEntriesFromItems(),
+		RPCExecFromItems(id, sequence, codec.EncodeRaw(codec.EncodeDouble(2))),
+		RPCResponseFromItems(id, sequence, codec.EncodeRaw(codec.EncodeDouble(3))),
+	}
+	for _, e := range entries {
+		messages = append(messages, EntryAssignFromEntry(e))
+	}
+	for _, update := range updates {
+		messages = append(messages, EntryUpdateFromUpdate(update))
+	}
+	seeds := [][]byte{
+		// a NetworkTables 2.0 Client Hello has no identity
+		{0x01, 0x02, 0x00},
+		// a string length whose LEB128 encoding never ends
+		{0x10, 0x80, 0x80, 0x80},
+		// a string claiming far more bytes than follow
+		{0x10, 0xff, 0xff, 0xff, 0x7f, 'a'},
+		// an array claiming more elements than follow
+		{0x11, 0x00, 0x01, 0x00, 0x02, 0x11, 0xff, 0x01},
+	}
+	for _, msg := range messages {
+		data := msg.CompressToBytes()
+		seeds = append(seeds, data, data[:len(data)/2])
+	}
+	return seeds
+}
+
+func FuzzBuildFromReader(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+	lookup := func(id [2]byte) (entry.EntryType, bool) {
+		return entry.TypeDouble, true
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) > 0 {
+			if msg, err := BuildFromReader(MessageType(data[0]), bytes.NewReader(data[1:])); err == nil {
+				msg.CompressToBytes()
+			}
+		}
+		// a stream of several messages, read as both protocol revisions
+		for _, rev2 := range []bool{false, true} {
+			decoder := NewDecoder(bytes.NewReader(data))
+			decoder.SetMaxLength(1 << 16)
+			for {
+				var msg IMessage
+				var err error
+				if rev2 {
+					msg, err = decoder.DecodeRev2(lookup)
+				} else {
+					msg, err = decoder.Decode()
+				}
+				if err != nil {
+					break
+				}
+				msg.CompressToBytes()
+			}
+			decoder.Release()
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package message
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/entryupdate"
+)
+
+// NetworkTables 2.0 shares its message type codes with 3.0 but only has the
+// keep alive, hello, entry assign and entry update messages. Strings are
+// prefixed by a 2 byte length, doubles are big endian, entries have no flags
+// and updates do not carry the entry's type.
+
+// EntryTypeLookup returns the type of the entry with the given ID. It is
+// needed to decode NetworkTables 2.0 updates, which do not include it.
+type EntryTypeLookup func(id [2]byte) (entry.EntryType, bool)
+
+// SupportedRev2 returns whether the message can be sent with NetworkTables 2.0,
+// which lacks most message types along with raw and RPC entries
+func SupportedRev2(msg IMessage) bool {
+	switch typed := msg.(type) {
+	case *KeepAlive, *ClientHello, *ProtoUnsupported, *ServerHelloComplete:
+		return true
+	case *EntryAssign:
+		return supportedTypeRev2(typed.GetEntry().GetType())
+	case *EntryUpdate:
+		return supportedTypeRev2(typed.GetUpdate().GetType())
+	default:
+		return false
+	}
+}
+
+func supportedTypeRev2(entryType entry.EntryType) bool {
+	return entryType != entry.TypeRaw && entryType != entry.TypeRPCDef
+}
+
+// BuildFromReaderRev2 identifies and builds the NetworkTables 2.0 message
+// with the same type as the message type passed in
+func BuildFromReaderRev2(messageType MessageType, reader io.Reader, lookup EntryTypeLookup) (IMessage, error) {
+	switch messageType {
+	case TypeKeepAlive:
+		return KeepAliveFromReader(), nil
+	case TypeClientHello:
+		var protocolRev [2]byte
+		if _, err := io.ReadFull(reader, protocolRev[:]); err != nil {
+			return nil, err
+		}
+		return &ClientHello{
+			protoRev: protocolRev,
+			Base: Base{
+				mType: TypeClientHello,
+				mData: protocolRev[:],
+			},
+		}, nil
+	case TypeProtoUnsupported:
+		return ProtoUnsupportedFromReader(reader)
+	case TypeServerHelloComplete:
+		return ServerHelloCompleteFromReader(), nil
+	case TypeEntryAssign:
+		name, err := readStringRev2(reader)
+		if err != nil {
+			return nil, err
+		}
+		var header [5]byte
+		if _, err = io.ReadFull(reader, header[:]); err != nil {
+			return nil, err
+		}
+		entryType := entry.EntryType(header[0])
+		value, err := readValueRev2(entryType, reader)
+		if err != nil {
+			return nil, err
+		}
+		var id, sequence [2]byte
+		copy(id[:], header[1:3])
+		copy(sequence[:], header[3:5])
+		assigned, err := entry.BuildFromValue(name, entryType, id, sequence, 0, value)
+		if err != nil {
+			return nil, err
+		}
+		return EntryAssignFromEntry(assigned), nil
+	case TypeEntryUpdate:
+		var header [4]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			return nil, err
+		}
+		var id, sequence [2]byte
+		copy(id[:], header[0:2])
+		copy(sequence[:], header[2:4])
+		entryType, ok := lookup(id)
+		if !ok {
+			return nil, fmt.Errorf("message: update for unknown entry %#x", id)
+		}
+		value, err := readValueRev2(entryType, reader)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := entry.BuildFromValue("", entryType, id, sequence, 0, value)
+		if err != nil {
+			return nil, err
+		}
+		update, err := entryupdate.BuildFromEntry(updated)
+		if err != nil {
+			return nil, err
+		}
+		return EntryUpdateFromUpdate(update), nil
+	default:
+		return nil, fmt.Errorf("message: %s is not a NetworkTables 2.0 message", messageType)
+	}
+}
+
+// CompressToBytesRev2 returns the message in its NetworkTables 2.0 byte array form
+func CompressToBytesRev2(msg IMessage) ([]byte, error) {
+	output := []byte{msg.GetType().Byte()}
+	switch typed := msg.(type) {
+	case *KeepAlive, *ServerHelloComplete:
+		return output, nil
+	case *ClientHello:
+		rev := typed.GetProtoRev()
+		return append(output, rev[:]...), nil
+	case *ProtoUnsupported:
+		rev := typed.GetSupportedProto()
+		return append(output, rev[:]...), nil
+	case *EntryAssign:
+		assigned := typed.GetEntry()
+		value, err := writeValueRev2(assigned.GetType(), assigned.GetValue())
+		if err != nil {
+			return nil, err
+		}
+		id := assigned.GetRawID()
+		output = append(output, writeStringRev2(assigned.GetName())...)
+		output = append(output, assigned.GetType().Byte())
+		output = append(output, id[:]...)
+		output = appendSequence(output, assigned.GetSequence())
+		return append(output, value...), nil
+	case *EntryUpdate:
+		update := typed.GetUpdate()
+		value, err := writeValueRev2(update.GetType(), update.GetValueUnsafe())
+		if err != nil {
+			return nil, err
+		}
+		id := update.GetRawID()
+		output = append(output, id[:]...)
+		output = appendSequence(output, update.GetSequence())
+		return append(output, value...), nil
+	default:
+		return nil, fmt.Errorf("message: %s is not a NetworkTables 2.0 message", msg.GetType())
+	}
+}
+
+func appendSequence(output []byte, sequence uint16) []byte {
+	var data [2]byte
+	binary.BigEndian.PutUint16(data[:], sequence)
+	return append(output, data[:]...)
+}
+
+func readStringRev2(reader io.Reader) (string, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(reader, length[:]); err != nil {
+		return "", err
+	}
+	data := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func writeStringRev2(value string) []byte {
+	output := make([]byte, 2, 2+len(value))
+	binary.BigEndian.PutUint16(output, uint16(len(value)))
+	return append(output, value...)
+}
+
+// readArrayLength reads the single byte element count of an array
+func readArrayLength(reader io.Reader) (int, error) {
+	var length [1]byte
+	_, err := io.ReadFull(reader, length[:])
+	return int(length[0]), err
+}
+
+func readValueRev2(entryType entry.EntryType, reader io.Reader) (interface{}, error) {
+	switch entryType {
+	case entry.TypeBoolean:
+		return codec.ReadBoolean(reader)
+	case entry.TypeDouble:
+		return codec.ReadDouble(reader)
+	case entry.TypeString:
+		return readStringRev2(reader)
+	case entry.TypeBooleanArr:
+		length, err := readArrayLength(reader)
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, length)
+		if _, err = io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		value := make([]bool, length)
+		for index, element := range data {
+			value[index] = element != 0
+		}
+		return value, nil
+	case entry.TypeDoubleArr:
+		length, err := readArrayLength(reader)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]float64, length)
+		for index := range value {
+			if value[index], err = codec.ReadDouble(reader); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	case entry.TypeStringArr:
+		length, err := readArrayLength(reader)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]string, length)
+		for index := range value {
+			if value[index], err = readStringRev2(reader); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("message: %s entries are not supported by NetworkTables 2.0", entryType)
+	}
+}
+
+func writeValueRev2(entryType entry.EntryType, value interface{}) ([]byte, error) {
+	var output []byte
+	ok := true
+	switch entryType {
+	case entry.TypeBoolean:
+		var val bool
+		if val, ok = value.(bool); ok {
+			output = codec.EncodeBoolean(val)
+		}
+	case entry.TypeDouble:
+		var val float64
+		if val, ok = value.(float64); ok {
+			output = codec.EncodeDouble(val)
+		}
+	case entry.TypeString:
+		var val string
+		if val, ok = value.(string); ok {
+			output = writeStringRev2(val)
+		}
+	case entry.TypeBooleanArr:
+		var val []bool
+		if val, ok = value.([]bool); ok && len(val) <= math.MaxUint8 {
+			output = []byte{byte(len(val))}
+			for _, element := range val {
+				output = append(output, codec.EncodeBoolean(element)...)
+			}
+		}
+	case entry.TypeDoubleArr:
+		var val []float64
+		if val, ok = value.([]float64); ok && len(val) <= math.MaxUint8 {
+			output = []byte{byte(len(val))}
+			for _, element := range val {
+				output = append(output, codec.EncodeDouble(element)...)
+			}
+		}
+	case entry.TypeStringArr:
+		var val []string
+		if val, ok = value.([]string); ok && len(val) <= math.MaxUint8 {
+			output = []byte{byte(len(val))}
+			for _, element := range val {
+				output = append(output, writeStringRev2(element)...)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("message: %s entries are not supported by NetworkTables 2.0", entryType)
+	}
+	if !ok || output == nil {
+		return nil, errors.New("message: value can not be encoded for NetworkTables 2.0")
+	}
+	return output, nil
+}
@@ -0,0 +1,52 @@
+package message
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRPCMessagesRoundTrip(t *testing.T) {
+	id := [2]byte{0x00, 0x01}
+	uniqueID := [2]byte{0xab, 0xcd}
+	data := bytes.Repeat([]byte{0x5a}, 300)
+	tests := []IMessage{
+		RPCExecFromItems(id, uniqueID, data),
+		RPCExecFromItems(id, uniqueID, []byte{}),
+		RPCResponseFromItems(id, uniqueID, data),
+		RPCResponseFromItems(id, uniqueID, []byte{}),
+	}
+	for _, msg := range tests {
+		encoded := msg.CompressToBytes()
+		if MessageType(encoded[0]) != msg.GetType() {
+			t.Fatalf("%s starts with type 0x%02x", msg.GetType(), encoded[0])
+		}
+		reader := bytes.NewReader(encoded[1:])
+		decoded, err := BuildFromReader(msg.GetType(), reader)
+		if err != nil {
+			t.Fatalf("%s: %s", msg.GetType(), err)
+		}
+		if reader.Len() != 0 {
+			t.Fatalf("%s: %d bytes were left unread", msg.GetType(), reader.Len())
+		}
+		if !bytes.Equal(decoded.CompressToBytes(), encoded) {
+			t.Fatalf("%s re-encoded as %x, want %x", msg.GetType(), decoded.CompressToBytes(), encoded)
+		}
+		switch decoded := decoded.(type) {
+		case *RPCExec:
+			if decoded.GetID() != id || decoded.GetUniqueID() != uniqueID || !bytes.Equal(decoded.GetParams(), msg.(*RPCExec).GetParams()) {
+				t.Fatalf("decoded %x, want %x", decoded.CompressToBytes(), encoded)
+			}
+		case *RPCResponse:
+			if decoded.GetID() != id || decoded.GetUniqueID() != uniqueID || !bytes.Equal(decoded.GetResults(), msg.(*RPCResponse).GetResults()) {
+				t.Fatalf("decoded %x, want %x", decoded.CompressToBytes(), encoded)
+			}
+		default:
+			t.Fatalf("decoded a %T", decoded)
+		}
+		for length := 1; length < len(encoded); length++ {
+			if _, err := BuildFromReader(msg.GetType(), bytes.NewReader(encoded[1:length])); err == nil {
+				t.Fatalf("%s: the first %d of %d bytes were accepted", msg.GetType(), length, len(encoded))
+			}
+		}
+	}
+}
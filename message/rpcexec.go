@@ -0,0 +1,76 @@
+package message
+
+import (
+	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+)
+
+// RPCExec message
+type RPCExec struct {
+	Base
+	id       [2]byte
+	uniqueID [2]byte
+	params   []byte
+}
+
+// RPCExecFromReader builds a new RPCExec message using the provided reader
+func RPCExecFromReader(reader io.Reader) (*RPCExec, error) {
+	var dID [2]byte
+	_, idErr := io.ReadFull(reader, dID[:])
+	if idErr != nil {
+		return nil, idErr
+	}
+	var dUniqueID [2]byte
+	_, uniqueErr := io.ReadFull(reader, dUniqueID[:])
+	if uniqueErr != nil {
+		return nil, uniqueErr
+	}
+	paramsData, paramsErr := codec.ReadRaw(reader)
+	if paramsErr != nil {
+		return nil, paramsErr
+	}
+	return RPCExecFromItems(dID, dUniqueID, paramsData), nil
+}
+
+// RPCExecFromItems builds a new RPCExec message using the provided parameters
+func RPCExecFromItems(dID [2]byte, dUniqueID [2]byte, params []byte) *RPCExec {
+	var totalData []byte
+	totalData = append(totalData, dID[:]...)
+	totalData = append(totalData, dUniqueID[:]...)
+	totalData = append(totalData, codec.EncodeRaw(params)...)
+	return &RPCExec{
+		id:       dID,
+		uniqueID: dUniqueID,
+		params:   params,
+		Base: Base{
+			mType: TypeRPCExec,
+			mData: totalData,
+		},
+	}
+}
+
+// GetID returns the ID of the RPC definition entry being executed
+func (rpcExec *RPCExec) GetID() [2]byte {
+	return rpcExec.id
+}
+
+// GetUniqueID returns the ID used to match the response to this call
+func (rpcExec *RPCExec) GetUniqueID() [2]byte {
+	return rpcExec.uniqueID
+}
+
+// GetParams returns the encoded parameter values
+func (rpcExec *RPCExec) GetParams() []byte {
+	return rpcExec.params
+}
+
+// CompressToBytes returns the message in its byte array form
+func (rpcExec *RPCExec) CompressToBytes() []byte {
+	return rpcExec.Base.compressToBytes()
+}
+
+// GetType returns the message's type
+func (rpcExec *RPCExec) GetType() MessageType {
+	return TypeRPCExec
+}
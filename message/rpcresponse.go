@@ -0,0 +1,76 @@
+package message
+
+import (
+	"io"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+)
+
+// RPCResponse message
+type RPCResponse struct {
+	Base
+	id       [2]byte
+	uniqueID [2]byte
+	results  []byte
+}
+
+// RPCResponseFromReader builds a new RPCResponse message using the provided reader
+func RPCResponseFromReader(reader io.Reader) (*RPCResponse, error) {
+	var dID [2]byte
+	_, idErr := io.ReadFull(reader, dID[:])
+	if idErr != nil {
+		return nil, idErr
+	}
+	var dUniqueID [2]byte
+	_, uniqueErr := io.ReadFull(reader, dUniqueID[:])
+	if uniqueErr != nil {
+		return nil, uniqueErr
+	}
+	resultsData, resultsErr := codec.ReadRaw(reader)
+	if resultsErr != nil {
+		return nil, resultsErr
+	}
+	return RPCResponseFromItems(dID, dUniqueID, resultsData), nil
+}
+
+// RPCResponseFromItems builds a new RPCResponse message using the provided parameters
+func RPCResponseFromItems(dID [2]byte, dUniqueID [2]byte, results []byte) *RPCResponse {
+	var totalData []byte
+	totalData = append(totalData, dID[:]...)
+	totalData = append(totalData, dUniqueID[:]...)
+	totalData = append(totalData, codec.EncodeRaw(results)...)
+	return &RPCResponse{
+		id:       dID,
+		uniqueID: dUniqueID,
+		results:  results,
+		Base: Base{
+			mType: TypeRPCResponse,
+			mData: totalData,
+		},
+	}
+}
+
+// GetID returns the ID of the RPC definition entry that was executed
+func (rpcResponse *RPCResponse) GetID() [2]byte {
+	return rpcResponse.id
+}
+
+// GetUniqueID returns the ID of the call this response answers
+func (rpcResponse *RPCResponse) GetUniqueID() [2]byte {
+	return rpcResponse.uniqueID
+}
+
+// GetResults returns the encoded result values
+func (rpcResponse *RPCResponse) GetResults() []byte {
+	return rpcResponse.results
+}
+
+// CompressToBytes returns the message in its byte array form
+func (rpcResponse *RPCResponse) CompressToBytes() []byte {
+	return rpcResponse.Base.compressToBytes()
+}
+
+// GetType returns the message's type
+func (rpcResponse *RPCResponse) GetType() MessageType {
+	return TypeRPCResponse
+}
@@ -1,10 +1,9 @@
 package message
 
 import (
-	"bytes"
 	"io"
 
-	"github.com/techplexengineer/frc-networktables-go/util"
+	"github.com/techplexengineer/frc-networktables-go/codec"
 )
 
 // ServerHello message
@@ -22,17 +21,11 @@ func ServerHelloFromReader(reader io.Reader) (*ServerHello, error) {
 		return nil, flagErr
 	}
 	firstConn := ((flags[0] & 1) == lsbFirstConnect)
-	identityLength, identitySizeData := util.PeekULeb128(reader)
-	identityData := make([]byte, identityLength)
-	_, identityErr := io.ReadFull(reader, identityData[:])
+	identity, identityErr := codec.ReadString(reader)
 	if identityErr != nil {
 		return nil, identityErr
 	}
-	identity := string(identityData[:])
-	var totalData []byte
-	totalData = append(totalData, flags[:]...)
-	totalData = append(totalData, identitySizeData...)
-	totalData = append(totalData, identityData[:]...)
+	totalData := append(flags[:], codec.EncodeString(identity)...)
 	return &ServerHello{
 		firstConnection: firstConn,
 		serverIdentity:  identity,
@@ -43,10 +36,13 @@ func ServerHelloFromReader(reader io.Reader) (*ServerHello, error) {
 	}, nil
 }
 
-// ServerHelloFromItems builds a new ServerHello message using the provided parameters
-func ServerHelloFromItems(flags byte, identity []byte) *ServerHello {
-	identityStrLen, identitySizeLen := util.ReadULeb128(bytes.NewBuffer(identity))
-	identityStr := string(identity[identitySizeLen:identityStrLen])
+// ServerHelloFromItems builds a new ServerHello message using the provided parameters.
+// An error is returned if the identity is not a single encoded string.
+func ServerHelloFromItems(flags byte, identity []byte) (*ServerHello, error) {
+	identityStr, err := codec.FromBytes(identity, codec.ReadString)
+	if err != nil {
+		return nil, err
+	}
 	firstConn := ((flags & 1) == lsbFirstConnect)
 	totalData := append([]byte{flags}, identity...)
 	return &ServerHello{
@@ -56,7 +52,7 @@ func ServerHelloFromItems(flags byte, identity []byte) *ServerHello {
 			mType: TypeServerHello,
 			mData: totalData,
 		},
-	}
+	}, nil
 }
 
 // IsFirstConnection returns if this is the first connection the client has made to the server
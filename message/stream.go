@@ -0,0 +1,161 @@
+package message
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+)
+
+// bufferSize is the size of the buffers connections are read and written through
+const bufferSize = 4096
+
+var (
+	// readerPool holds the buffered readers of decoders that have been released
+	readerPool = sync.Pool{
+		New: func() interface{} {
+			return bufio.NewReaderSize(nil, bufferSize)
+		},
+	}
+	// bufferPool holds the buffers of encoders that have been released
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			buffer := make([]byte, 0, bufferSize)
+			return &buffer
+		},
+	}
+)
+
+// appender is implemented by messages that can append their byte array form
+// to a buffer instead of making a new one
+type appender interface {
+	appendTo(output []byte) []byte
+}
+
+// Decoder reads messages from a stream through a pooled buffer, so that the
+// message type and the header of each message are read without allocating.
+// Strings and raw values longer than its limit are rejected with a
+// codec.LengthError.
+type Decoder struct {
+	buffered *bufio.Reader
+	reader   io.Reader
+}
+
+// NewDecoder returns a decoder reading from the reader, limiting values to
+// codec.DefaultMaxLength bytes. It must be released once it is no longer used.
+func NewDecoder(reader io.Reader) *Decoder {
+	buffered := readerPool.Get().(*bufio.Reader)
+	buffered.Reset(reader)
+	return &Decoder{
+		buffered: buffered,
+		reader:   codec.LimitReader(buffered, codec.DefaultMaxLength),
+	}
+}
+
+// SetMaxLength changes the number of bytes a string or raw value may hold
+func (decoder *Decoder) SetMaxLength(maxLength int) {
+	decoder.reader = codec.LimitReader(decoder.buffered, maxLength)
+}
+
+// Decode reads the next message
+func (decoder *Decoder) Decode() (IMessage, error) {
+	messageType, err := decoder.buffered.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	return BuildFromReader(MessageType(messageType), decoder.reader)
+}
+
+// DecodeRev2 reads the next NetworkTables 2.0 message
+func (decoder *Decoder) DecodeRev2(lookup EntryTypeLookup) (IMessage, error) {
+	messageType, err := decoder.buffered.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	return BuildFromReaderRev2(MessageType(messageType), decoder.reader, lookup)
+}
+
+// Release returns the decoder's buffer to the pool. The decoder must not be
+// used afterwards.
+func (decoder *Decoder) Release() {
+	if decoder.buffered == nil {
+		return
+	}
+	decoder.buffered.Reset(nil)
+	readerPool.Put(decoder.buffered)
+	decoder.buffered = nil
+	decoder.reader = nil
+}
+
+// Encoder collects messages in a pooled buffer, and writes them to a stream
+// together when flushed
+type Encoder struct {
+	writer io.Writer
+	buffer *[]byte
+}
+
+// NewEncoder returns an encoder writing to the writer. It must be released
+// once it is no longer used.
+func NewEncoder(writer io.Writer) *Encoder {
+	return &Encoder{
+		writer: writer,
+		buffer: bufferPool.Get().(*[]byte),
+	}
+}
+
+// Reset discards the buffered messages and writes to the writer from now on
+func (encoder *Encoder) Reset(writer io.Writer) {
+	encoder.writer = writer
+	*encoder.buffer = (*encoder.buffer)[:0]
+}
+
+// Encode adds the message to the buffer
+func (encoder *Encoder) Encode(msg IMessage) {
+	if typed, ok := msg.(appender); ok {
+		*encoder.buffer = typed.appendTo(*encoder.buffer)
+		return
+	}
+	*encoder.buffer = append(*encoder.buffer, msg.CompressToBytes()...)
+}
+
+// EncodeRev2 adds the NetworkTables 2.0 form of the message to the buffer
+func (encoder *Encoder) EncodeRev2(msg IMessage) error {
+	data, err := CompressToBytesRev2(msg)
+	if err != nil {
+		return err
+	}
+	*encoder.buffer = append(*encoder.buffer, data...)
+	return nil
+}
+
+// Buffered returns the number of bytes waiting to be written
+func (encoder *Encoder) Buffered() int {
+	return len(*encoder.buffer)
+}
+
+// Flush writes the buffered messages to the writer in a single write. The
+// buffer is emptied even if the write fails.
+func (encoder *Encoder) Flush() error {
+	if len(*encoder.buffer) == 0 {
+		return nil
+	}
+	_, err := encoder.writer.Write(*encoder.buffer)
+	*encoder.buffer = (*encoder.buffer)[:0]
+	return err
+}
+
+// Release returns the encoder's buffer to the pool, discarding anything not
+// yet flushed. The encoder must not be used afterwards.
+func (encoder *Encoder) Release() {
+	if encoder.buffer == nil {
+		return
+	}
+	if cap(*encoder.buffer) <= 16*bufferSize {
+		// a buffer grown by an unusually large batch is left to the garbage collector
+		*encoder.buffer = (*encoder.buffer)[:0]
+		bufferPool.Put(encoder.buffer)
+	}
+	encoder.buffer = nil
+	encoder.writer = nil
+}
@@ -0,0 +1,80 @@
+package message
+
+import (
+	"io"
+	"testing"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/entryupdate"
+)
+
+// repeatReader reads the same messages over and over, so a decoder never
+// runs out of them
+type repeatReader struct {
+	data   []byte
+	offset int
+}
+
+func (reader *repeatReader) Read(buffer []byte) (int, error) {
+	n := copy(buffer, reader.data[reader.offset:])
+	reader.offset = (reader.offset + n) % len(reader.data)
+	return n, nil
+}
+
+// benchmarkDecode decodes the message from a stream of copies of it
+func benchmarkDecode(b *testing.B, msg IMessage) {
+	b.Helper()
+	var data []byte
+	for len(data) < bufferSize {
+		data = append(data, msg.CompressToBytes()...)
+	}
+	decoder := NewDecoder(&repeatReader{data: data})
+	defer decoder.Release()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decoded, err := decoder.Decode()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if decoded.GetType() != msg.GetType() {
+			b.Fatalf("decoded a %s, want a %s", decoded.GetType(), msg.GetType())
+		}
+	}
+}
+
+func BenchmarkDecodeEntryAssign(b *testing.B) {
+	assigned := entry.DoubleFromValue("/drive/speed", [2]byte{0x00, 0x01}, [2]byte{0x00, 0x07}, 0x00, 1.5)
+	benchmarkDecode(b, EntryAssignFromEntry(assigned))
+}
+
+func BenchmarkDecodeEntryUpdate(b *testing.B) {
+	update := entryupdate.DoubleFromValue([2]byte{0x00, 0x01}, [2]byte{0x00, 0x07}, 1.5)
+	benchmarkDecode(b, EntryUpdateFromUpdate(update))
+}
+
+// benchmarkEncode encodes the message over and over, flushing the encoder
+// whenever its buffer fills up
+func benchmarkEncode(b *testing.B, msg IMessage) {
+	b.Helper()
+	encoder := NewEncoder(io.Discard)
+	defer encoder.Release()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoder.Encode(msg)
+		if encoder.Buffered() >= bufferSize {
+			encoder.Flush()
+		}
+	}
+}
+
+func BenchmarkEncodeEntryAssign(b *testing.B) {
+	assigned := entry.DoubleFromValue("/drive/speed", [2]byte{0x00, 0x01}, [2]byte{0x00, 0x07}, 0x00, 1.5)
+	benchmarkEncode(b, EntryAssignFromEntry(assigned))
+}
+
+func BenchmarkEncodeEntryUpdate(b *testing.B) {
+	update := entryupdate.DoubleFromValue([2]byte{0x00, 0x01}, [2]byte{0x00, 0x07}, 1.5)
+	benchmarkEncode(b, EntryUpdateFromUpdate(update))
+}
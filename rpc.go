@@ -0,0 +1,79 @@
+package frcntgo
+
+import (
+	"fmt"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/message"
+)
+
+// RPCHandler executes a remote procedure served from Go. It receives the
+// decoded parameter values, in the order of the definition's parameters,
+// and returns one value per result of the definition.
+type RPCHandler func(params []interface{}) ([]interface{}, error)
+
+// executeRPC runs the handler for an RPCExec message and builds the matching
+// response. If the parameters do not decode according to the definition the
+// call is ignored and nil is returned. A failing handler still produces a
+// response, with every result set to its zero value, as even calls with
+// zero outputs must respond.
+func executeRPC(logger Logger, definition entry.RPCDefinition, handler RPCHandler, exec *message.RPCExec) *message.RPCResponse {
+	params, err := definition.DecodeParams(exec.GetParams())
+	if err != nil {
+		logger.Warnf("rpc: ignoring call to %s: %s", definition.Name, err)
+		return nil
+	}
+	results, err := callRPCHandler(handler, params)
+	if err != nil {
+		logger.Warnf("rpc: %s failed: %s", definition.Name, err)
+		results = zeroResults(definition)
+	}
+	resultData, err := definition.EncodeResults(results)
+	if err != nil {
+		logger.Errorf("rpc: %s returned invalid results: %s", definition.Name, err)
+		resultData, _ = definition.EncodeResults(zeroResults(definition))
+	}
+	return message.RPCResponseFromItems(exec.GetID(), exec.GetUniqueID(), resultData)
+}
+
+// callRPCHandler calls the handler, turning a panic into an error so a
+// misbehaving procedure can not take the connection down with it
+func callRPCHandler(handler RPCHandler, params []interface{}) (results []interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(params)
+}
+
+// zeroResults returns the zero value of every result of the definition
+func zeroResults(definition entry.RPCDefinition) []interface{} {
+	results := make([]interface{}, len(definition.Results))
+	for index, result := range definition.Results {
+		results[index] = zeroValue(result.Type)
+	}
+	return results
+}
+
+// zeroValue returns the zero value of an entry type
+func zeroValue(entryType entry.EntryType) interface{} {
+	switch entryType {
+	case entry.TypeBoolean:
+		return false
+	case entry.TypeDouble:
+		return float64(0)
+	case entry.TypeString:
+		return ""
+	case entry.TypeRaw:
+		return []byte{}
+	case entry.TypeBooleanArr:
+		return []bool{}
+	case entry.TypeDoubleArr:
+		return []float64{}
+	case entry.TypeStringArr:
+		return []string{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,259 @@
+package frcntgo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/message"
+)
+
+// addDefinition adds two doubles, which default to one
+var addDefinition = entry.RPCDefinition{
+	Name: "add",
+	Params: []entry.RPCParam{
+		{Type: entry.TypeDouble, Name: "a", Default: 1.0},
+		{Type: entry.TypeDouble, Name: "b", Default: 1.0},
+	},
+	Results: []entry.RPCResult{{Type: entry.TypeDouble, Name: "sum"}},
+}
+
+// add is the handler of addDefinition
+func add(params []interface{}) ([]interface{}, error) {
+	return []interface{}{params[0].(float64) + params[1].(float64)}, nil
+}
+
+// pendingRPCCalls returns how many calls of the client wait on a response
+func pendingRPCCalls(client *Client) int {
+	client.rpcMutex.Lock()
+	defer client.rpcMutex.Unlock()
+	return len(client.rpcCalls)
+}
+
+func TestCallRPCServedByServer(t *testing.T) {
+	server, port := startServer(t)
+	release := make(chan struct{})
+	err := server.RegisterRPC("/slow add", addDefinition, func(params []interface{}) ([]interface{}, error) {
+		<-release
+		return add(params)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := startClient(t, port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if results, err := client.CallRPC(ctx, "/slow add", []interface{}{2.0, 3.0}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("cancelled call returned %v, %v", results, err)
+	}
+	if pending := pendingRPCCalls(client); pending != 0 {
+		t.Fatalf("%d calls are still pending after the cancelled call", pending)
+	}
+
+	// the response to the cancelled call arrives late and is dropped
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results, err := client.CallRPC(ctx, "/slow add", []interface{}{2.0, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, []interface{}{3.0}) {
+		t.Fatalf("call returned %v, want [3]", results)
+	}
+	if pending := pendingRPCCalls(client); pending != 0 {
+		t.Fatalf("%d calls are still pending", pending)
+	}
+
+	if _, err := client.CallRPC(ctx, "/slow add", []interface{}{"two", 3.0}); err == nil {
+		t.Error("a call with a parameter of the wrong type was sent")
+	}
+	var notFound *KeyNotFoundError
+	if _, err := client.CallRPC(ctx, "/missing", nil); !errors.As(err, &notFound) {
+		t.Errorf("calling a missing procedure returned %v", err)
+	}
+}
+
+// forwardedRPCCalls returns the calls the server forwarded to their owner
+// that are still waiting on a response
+func forwardedRPCCalls(server *Server) map[rpcCall]rpcForward {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	forwards := map[rpcCall]rpcForward{}
+	for call, forward := range server.rpcForwards {
+		forwards[call] = forward
+	}
+	return forwards
+}
+
+// callRPC calls the procedure, failing the test if it does not respond soon
+func callRPC(t *testing.T, client *Client, key string, params []interface{}) []interface{} {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results, err := client.CallRPC(ctx, key, params)
+	if err != nil {
+		t.Fatalf("calling %s: %s", key, err)
+	}
+	return results
+}
+
+func TestExecuteRPC(t *testing.T) {
+	exec := func(params []interface{}) *message.RPCExec {
+		data, err := addDefinition.EncodeParams(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return message.RPCExecFromItems([2]byte{0x00, 0x01}, [2]byte{0x00, 0x02}, data)
+	}
+	tests := []struct {
+		name    string
+		handler RPCHandler
+		want    []interface{}
+	}{
+		{"handler", add, []interface{}{5.0}},
+		{"error", func([]interface{}) ([]interface{}, error) {
+			return nil, errors.New("failed")
+		}, []interface{}{0.0}},
+		{"panic", func([]interface{}) ([]interface{}, error) {
+			panic("failed")
+		}, []interface{}{0.0}},
+		{"wrong result type", func([]interface{}) ([]interface{}, error) {
+			return []interface{}{"five"}, nil
+		}, []interface{}{0.0}},
+		{"too many results", func([]interface{}) ([]interface{}, error) {
+			return []interface{}{5.0, 6.0}, nil
+		}, []interface{}{0.0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := executeRPC(quietLogger, addDefinition, test.handler, exec([]interface{}{2.0, 3.0}))
+			if response == nil {
+				t.Fatal("no response")
+			}
+			if response.GetID() != [2]byte{0x00, 0x01} || response.GetUniqueID() != [2]byte{0x00, 0x02} {
+				t.Fatalf("responded to %#x/%#x", response.GetID(), response.GetUniqueID())
+			}
+			results, err := addDefinition.DecodeResults(response.GetResults())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(results, test.want) {
+				t.Fatalf("results are %v, want %v", results, test.want)
+			}
+		})
+	}
+
+	malformed := message.RPCExecFromItems([2]byte{0x00, 0x01}, [2]byte{0x00, 0x02}, []byte{0x01})
+	if response := executeRPC(quietLogger, addDefinition, add, malformed); response != nil {
+		t.Errorf("responded to a call with malformed parameters")
+	}
+}
+
+func TestServerRPCHandlerPanics(t *testing.T) {
+	server, port := startServer(t)
+	err := server.RegisterRPC("/panic", addDefinition, func([]interface{}) ([]interface{}, error) {
+		panic("failed")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := startClient(t, port)
+	if results := callRPC(t, client, "/panic", []interface{}{2.0, 3.0}); !reflect.DeepEqual(results, []interface{}{0.0}) {
+		t.Fatalf("call returned %v, want [0]", results)
+	}
+	// the server still serves calls after the panic
+	if results := callRPC(t, client, "/panic", []interface{}{nil, nil}); !reflect.DeepEqual(results, []interface{}{0.0}) {
+		t.Fatalf("call returned %v, want [0]", results)
+	}
+}
+
+func TestClientRPCForwardedByServer(t *testing.T) {
+	server, port := startServer(t)
+	owner := startClient(t, port)
+	first := startClient(t, port)
+	second := startClient(t, port)
+
+	// the handler holds every call until both callers are waiting on it
+	release := make(chan struct{})
+	err := owner.RegisterRPC("/add", addDefinition, func(params []interface{}) ([]interface{}, error) {
+		<-release
+		return add(params)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := owner.RegisterRPC("/panic", addDefinition, func([]interface{}) ([]interface{}, error) {
+		panic("failed")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, caller := range []*Client{first, second} {
+		caller := caller
+		eventually(t, func() bool {
+			return caller.GetEntry("/add") != nil && caller.GetEntry("/panic") != nil
+		}, "the procedures never reached a caller")
+	}
+
+	// both callers use the same unique ID for their first call, so the server
+	// must give the calls it forwards unique IDs of its own
+	type result struct {
+		results []interface{}
+		err     error
+	}
+	firstDone := make(chan result, 1)
+	secondDone := make(chan result, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		results, err := first.CallRPC(ctx, "/add", []interface{}{1.0, 2.0})
+		firstDone <- result{results, err}
+	}()
+	go func() {
+		results, err := second.CallRPC(ctx, "/add", []interface{}{10.0, 20.0})
+		secondDone <- result{results, err}
+	}()
+	eventually(t, func() bool { return len(forwardedRPCCalls(server)) == 2 }, "the server never forwarded both calls")
+	for call, forward := range forwardedRPCCalls(server) {
+		if forward.uniqueID != [2]byte{0x00, 0x01} {
+			t.Errorf("forwarded call %#x was %#x for its caller, want 0x0001", call.uniqueID, forward.uniqueID)
+		}
+	}
+	close(release)
+	for caller, done := range map[string]chan result{"first": firstDone, "second": secondDone} {
+		want := map[string][]interface{}{"first": {3.0}, "second": {30.0}}[caller]
+		got := <-done
+		if got.err != nil {
+			t.Fatalf("%s caller: %s", caller, got.err)
+		}
+		if !reflect.DeepEqual(got.results, want) {
+			t.Errorf("%s caller received %v, want %v", caller, got.results, want)
+		}
+	}
+	if forwards := forwardedRPCCalls(server); len(forwards) != 0 {
+		t.Fatalf("%d forwarded calls were never cleaned up", len(forwards))
+	}
+
+	if results := callRPC(t, first, "/panic", []interface{}{2.0, 3.0}); !reflect.DeepEqual(results, []interface{}{0.0}) {
+		t.Fatalf("call to a panicking procedure returned %v, want [0]", results)
+	}
+
+	if err := owner.UnregisterRPC("/add"); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool { return first.GetEntry("/add") == nil }, "the unregistered procedure never left the caller")
+	var notFound *KeyNotFoundError
+	if _, err := first.CallRPC(ctx, "/add", nil); !errors.As(err, &notFound) {
+		t.Fatalf("calling an unregistered procedure returned %v", err)
+	}
+	server.mutex.Lock()
+	_, owned := server.rpcOwners["/add"]
+	server.mutex.Unlock()
+	if owned {
+		t.Fatal("the server still forwards calls to the unregistered procedure")
+	}
+}
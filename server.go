@@ -1,4 +1,750 @@
 package frcntgo
 
-// Server is the NetworkTables Server
-//type Server struct{}
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/entryupdate"
+	"github.com/techplexengineer/frc-networktables-go/message"
+	"github.com/techplexengineer/frc-networktables-go/storage"
+	"github.com/techplexengineer/frc-networktables-go/util"
+)
+
+const (
+	// serverQueueSize is the number of messages that may be waiting to be
+	// written to a single client before it is considered too slow and dropped
+	serverQueueSize = 1024
+	// maxWriteSize is the size after which queued messages stop being
+	// gathered into the write to a client
+	maxWriteSize = 64 * 1024
+)
+
+var (
+	// protocolRev3 is the protocol revision spoken by the server, and
+	// protocolRev2 the older one clients fall back to
+	protocolRev3 = [2]byte{0x03, 0x00}
+	protocolRev2 = [2]byte{0x02, 0x00}
+	// unassignedID is the ID used by clients for entries they create
+	unassignedID = [2]byte{0xFF, 0xFF}
+)
+
+// Server is the NetworkTables Server. Its entries are shared with the
+// messages sent to clients, so they are replaced rather than changed in place.
+type Server struct {
+	identity string
+
+	mutex    sync.Mutex
+	listener net.Listener
+	closed   bool
+	entries  map[string]entry.IEntry
+	ids      map[[2]byte]string
+	nextID   uint16
+	clients  map[*serverClient]bool
+	// seen holds the identity of every client that has connected since the
+	// server started, to fill in the reconnect flag of the ServerHello
+	seen map[string]bool
+
+	// rpcHandlers holds the procedures served by the server and rpcOwners the
+	// clients serving the procedures they created, both by entry name.
+	// rpcForwards maps calls forwarded to an owner back to the caller.
+	rpcHandlers map[string]RPCHandler
+	rpcOwners   map[string]*serverClient
+	rpcForwards map[rpcCall]rpcForward
+	rpcUniqueID uint16
+
+	logger Logger
+	// maxValueLength is the number of bytes a string or raw value received
+	// from a client may hold
+	maxValueLength int
+	// keepAliveInterval is how long a client's connection may be idle before
+	// a KeepAlive is sent to it
+	keepAliveInterval time.Duration
+
+	// done is closed when the server is closed
+	done chan struct{}
+}
+
+// rpcForward is a call that was forwarded to the client serving the procedure
+type rpcForward struct {
+	caller   *serverClient
+	uniqueID [2]byte
+}
+
+// serverClient is a single connection to the server
+type serverClient struct {
+	server   *Server
+	conn     net.Conn
+	identity string
+	// outgoing is made during the handshake, before the client can be sent
+	// anything other than a ProtoUnsupported
+	outgoing chan message.IMessage
+	done     chan struct{}
+	once     sync.Once
+}
+
+// ServerOption configures optional behaviour of a Server
+type ServerOption func(*Server)
+
+// WithServerLogger sends the server's log messages to the logger
+func WithServerLogger(logger Logger) ServerOption {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithServerMaxValueLength changes the number of bytes a string or raw value
+// received from a client may hold. A client sending a longer value is
+// disconnected.
+func WithServerMaxValueLength(maxLength int) ServerOption {
+	return func(s *Server) {
+		s.maxValueLength = maxLength
+	}
+}
+
+// WithServerKeepAlive changes how long a client's connection may be idle
+// before a KeepAlive is sent to it. Clients give up on a server that is
+// silent for their keep alive timeout, which must be longer than this
+// interval. An interval that is not positive keeps the default of one second.
+func WithServerKeepAlive(interval time.Duration) ServerOption {
+	return func(s *Server) {
+		if interval > 0 {
+			s.keepAliveInterval = interval
+		}
+	}
+}
+
+// NewServer creates a new Network Tables server that identifies itself with the given name
+func NewServer(identity string, options ...ServerOption) *Server {
+	server := &Server{
+		identity:       identity,
+		entries:        map[string]entry.IEntry{},
+		ids:            map[[2]byte]string{},
+		clients:        map[*serverClient]bool{},
+		seen:           map[string]bool{},
+		rpcHandlers:    map[string]RPCHandler{},
+		rpcOwners:      map[string]*serverClient{},
+		rpcForwards:    map[rpcCall]rpcForward{},
+		logger:         defaultLogger(),
+		maxValueLength: codec.DefaultMaxLength,
+		done:           make(chan struct{}),
+
+		keepAliveInterval: keepAliveInterval,
+	}
+	for _, option := range options {
+		option(server)
+	}
+	return server
+}
+
+// ListenAndServe listens for clients on the given address and port and
+// serves them until the server is closed. Usually Network Tables uses port 1735.
+func (s *Server) ListenAndServe(listenAddr, listenPort string) error {
+	listener, err := net.Listen("tcp", util.ConcatAddress(listenAddr, listenPort))
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts clients on the listener until the server is closed
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		listener.Close()
+		return errors.New("server: Already closed")
+	}
+	s.listener = listener
+	s.mutex.Unlock()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		sc := &serverClient{
+			server: s,
+			conn:   conn,
+			done:   make(chan struct{}),
+		}
+		// the outgoing queue is made and written once the client says hello
+		go sc.receiveIncoming()
+	}
+}
+
+// Close stops accepting clients and disconnects every connected client
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return errors.New("server: Already closed")
+	}
+	s.closed = true
+	close(s.done)
+	listener := s.listener
+	clients := s.clients
+	s.clients = map[*serverClient]bool{}
+	s.mutex.Unlock()
+	for sc := range clients {
+		sc.close()
+	}
+	if listener != nil {
+		return listener.Close()
+	}
+	return nil
+}
+
+// GetEntry returns a copy of the entry at the specified key, or nil if there is none
+func (s *Server) GetEntry(key string) entry.IEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	existing, ok := s.entries[util.SanitizeKey(key)]
+	if !ok {
+		return nil
+	}
+	copied, err := copyEntry(existing, existing.GetSequence(), existing.GetFlags(), existing.GetValue())
+	if err != nil {
+		return nil
+	}
+	return copied
+}
+
+// GetKeys returns every key starting with the prefix
+func (s *Server) GetKeys(prefix string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	keys := []string{}
+	for k := range s.entries {
+		if prefix == "" || strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// SetValue updates the entry at the specified key, creating it if it does not
+// exist, and informs every connected client
+func (s *Server) SetValue(key string, entryType entry.EntryType, value interface{}) error {
+	key = util.SanitizeKey(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	existing, ok := s.entries[key]
+	if !ok {
+		newEntry, err := entry.BuildFromValue(key, entryType, unassignedID, [2]byte{}, 0, value)
+		if err != nil {
+			return err
+		}
+		return s.assignEntry(newEntry)
+	}
+	if existing.GetType() != entryType {
+		return fmt.Errorf("server: entry %s is a %s, not a %s", key, existing.GetType(), entryType)
+	}
+	if reflect.DeepEqual(existing.GetValue(), value) {
+		// only send an update if the value has changed
+		return nil
+	}
+	var sequence [2]byte
+	binary.BigEndian.PutUint16(sequence[:], existing.GetSequence()+1)
+	updated, err := entry.BuildFromValue(key, entryType, existing.GetRawID(), sequence, existing.GetFlags(), value)
+	if err != nil {
+		return err
+	}
+	update, err := entryupdate.BuildFromEntry(updated)
+	if err != nil {
+		return err
+	}
+	s.entries[key] = updated
+	s.broadcast(message.EntryUpdateFromUpdate(update), nil)
+	return nil
+}
+
+// SetPersistent marks the entry at the specified key as persistent, or clears the flag
+func (s *Server) SetPersistent(key string, persistent bool) error {
+	key = util.SanitizeKey(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	existing, ok := s.entries[key]
+	if !ok {
+		return fmt.Errorf("server: entry %s does not exist", key)
+	}
+	if existing.IsPersistent() == persistent {
+		return nil
+	}
+	flags := existing.GetFlags() &^ entry.FlagPersistent
+	if persistent {
+		flags |= entry.FlagPersistent
+	}
+	updated, err := copyEntry(existing, existing.GetSequence(), flags, existing.GetValue())
+	if err != nil {
+		return err
+	}
+	s.entries[key] = updated
+	s.broadcast(message.EntryFlagUpdateFromItems(updated.GetRawID(), updated.GetFlags()), nil)
+	return nil
+}
+
+// Delete removes the entry at the specified key and informs every connected client
+func (s *Server) Delete(key string) error {
+	key = util.SanitizeKey(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	existing, ok := s.entries[key]
+	if !ok {
+		return fmt.Errorf("server: entry %s does not exist", key)
+	}
+	s.deleteEntry(existing)
+	s.broadcast(message.EntryDeleteFromItems(existing.GetRawID()), nil)
+	return nil
+}
+
+// LoadPersistent creates the entries saved in a persistent file. Entries that
+// already exist are updated with the saved values. Every loaded entry is
+// flagged as persistent.
+func (s *Server) LoadPersistent(path string) error {
+	saved, err := storage.Load(path)
+	if err != nil {
+		return err
+	}
+	for _, e := range saved {
+		err = s.SetValue(e.Name, e.Type, e.Value)
+		if err == nil {
+			err = s.SetPersistent(e.Name, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SavePersistent writes every entry flagged as persistent to a persistent file
+func (s *Server) SavePersistent(path string) error {
+	return storage.Save(path, s.persistentEntries())
+}
+
+// PersistTo restores the entries saved at path, if the file exists, then
+// saves the persistent entries back to it every period in which they changed,
+// until the server is closed.
+func (s *Server) PersistTo(path string, period time.Duration) error {
+	err := s.LoadPersistent(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		lastSaved := s.persistentEntries()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+			current := s.persistentEntries()
+			if reflect.DeepEqual(current, lastSaved) {
+				continue
+			}
+			err := storage.Save(path, current)
+			if err != nil {
+				s.logger.Errorf("server: unable to save persistent entries: %s", err)
+				continue
+			}
+			lastSaved = current
+		}
+	}()
+	return nil
+}
+
+// persistentEntries returns every entry flagged as persistent, sorted by name
+func (s *Server) persistentEntries() []storage.Entry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	persistent := []storage.Entry{}
+	for name, e := range s.entries {
+		if e.IsPersistent() && e.GetType() != entry.TypeRPCDef {
+			persistent = append(persistent, storage.Entry{Name: name, Type: e.GetType(), Value: e.GetValue()})
+		}
+	}
+	sort.Slice(persistent, func(i, j int) bool {
+		return persistent[i].Name < persistent[j].Name
+	})
+	return persistent
+}
+
+// RegisterRPC publishes a remote procedure definition at the specified key
+// and serves calls to it with the handler. Each call runs in its own goroutine.
+func (s *Server) RegisterRPC(key string, definition entry.RPCDefinition, handler RPCHandler) error {
+	key = util.SanitizeKey(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.entries[key]; ok {
+		return fmt.Errorf("server: entry %s already exists", key)
+	}
+	newEntry, err := entry.RPCDefFromValue(key, unassignedID, [2]byte{}, 0, definition)
+	if err != nil {
+		return err
+	}
+	if err = s.assignEntry(newEntry); err != nil {
+		return err
+	}
+	s.rpcHandlers[key] = handler
+	return nil
+}
+
+// assignEntry gives a new entry the next free ID, stores it and announces it
+// to every connected client. The server's mutex must be held.
+func (s *Server) assignEntry(newEntry entry.IEntry) error {
+	var id [2]byte
+	for {
+		binary.BigEndian.PutUint16(id[:], s.nextID)
+		s.nextID++
+		if _, used := s.ids[id]; !used && id != unassignedID {
+			break
+		}
+	}
+	// the sequence number is the server's to give, whatever a client sent
+	// along with the entry
+	assigned, err := entry.BuildFromValue(newEntry.GetName(), newEntry.GetType(), id, [2]byte{}, newEntry.GetFlags(), newEntry.GetValue())
+	if err != nil {
+		return err
+	}
+	s.entries[assigned.GetName()] = assigned
+	s.ids[id] = assigned.GetName()
+	s.broadcast(message.EntryAssignFromEntry(assigned), nil)
+	return nil
+}
+
+// deleteEntry removes an entry from the store. The server's mutex must be held.
+func (s *Server) deleteEntry(existing entry.IEntry) {
+	delete(s.entries, existing.GetName())
+	delete(s.ids, existing.GetRawID())
+	delete(s.rpcHandlers, existing.GetName())
+	delete(s.rpcOwners, existing.GetName())
+}
+
+// broadcast queues the message for every connected client other than the
+// one passed in. The server's mutex must be held.
+func (s *Server) broadcast(msg message.IMessage, except *serverClient) {
+	for sc := range s.clients {
+		if sc != except {
+			sc.send(msg)
+		}
+	}
+}
+
+// entryByID returns the entry with the given ID. The server's mutex must be held.
+func (s *Server) entryByID(id [2]byte) (entry.IEntry, bool) {
+	name, ok := s.ids[id]
+	if !ok {
+		return nil, false
+	}
+	return s.entries[name], true
+}
+
+// send queues a message for the client, dropping the client if it can not keep up
+func (sc *serverClient) send(msg message.IMessage) {
+	sc.server.logger.Debugf("server: <=== sending %s to %s", msg.GetType(), sc.conn.RemoteAddr())
+	select {
+	case sc.outgoing <- msg:
+	case <-sc.done:
+	default:
+		sc.server.logger.Warnf("server: %s is not keeping up, disconnecting", sc.conn.RemoteAddr())
+		sc.close()
+	}
+}
+
+// close disconnects the client and removes it from the server
+func (sc *serverClient) close() {
+	sc.once.Do(func() {
+		close(sc.done)
+		sc.conn.Close()
+		go sc.server.removeClient(sc)
+	})
+}
+
+// removeClient forgets a disconnected client
+func (s *Server) removeClient(sc *serverClient) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.clients, sc)
+	for name, owner := range s.rpcOwners {
+		if owner == sc {
+			delete(s.rpcOwners, name)
+		}
+	}
+	for call, forward := range s.rpcForwards {
+		if forward.caller == sc {
+			delete(s.rpcForwards, call)
+		}
+	}
+}
+
+// processOutgoingQueue writes queued messages to the client, should be called
+// as a gofun. A KeepAlive is sent whenever nothing was written for the keep
+// alive interval, so clients can tell an idle server from a dead one.
+func (sc *serverClient) processOutgoingQueue() {
+	idle := time.NewTimer(sc.server.keepAliveInterval)
+	defer idle.Stop()
+	encoder := message.NewEncoder(sc.conn)
+	defer encoder.Release()
+	for {
+		var sending message.IMessage
+		select {
+		case <-sc.done:
+			return
+		case sending = <-sc.outgoing:
+		case <-idle.C:
+			sending = message.KeepAliveFromItems()
+		}
+		resetTimer(idle, sc.server.keepAliveInterval)
+		encoder.Encode(sending)
+		// whatever else is already queued goes out in the same write
+		for queued := len(sc.outgoing); queued > 0 && encoder.Buffered() < maxWriteSize; queued-- {
+			encoder.Encode(<-sc.outgoing)
+		}
+		if err := encoder.Flush(); err != nil {
+			sc.close()
+			return
+		}
+	}
+}
+
+// receiveIncoming reads and handles messages from the client, should be called as a gofun
+func (sc *serverClient) receiveIncoming() {
+	defer sc.close()
+	decoder := message.NewDecoder(sc.conn)
+	defer decoder.Release()
+	decoder.SetMaxLength(sc.server.maxValueLength)
+	for {
+		tempPacket, err := decoder.Decode()
+		if err != nil {
+			var netError net.Error
+			select {
+			case <-sc.done:
+				// closed by the server
+			default:
+				if errors.As(err, &netError) || errors.Is(err, io.ErrUnexpectedEOF) {
+					sc.server.logger.Warnf("server: io error: %s", err)
+				} else if err != io.EOF {
+					sc.server.logger.Errorf("server: message error: %s", err)
+				}
+			}
+			return
+		}
+		sc.server.logger.Debugf("server: ===> got %s from %s", tempPacket.GetType(), sc.conn.RemoteAddr())
+		if !sc.handleMessage(tempPacket) {
+			return
+		}
+	}
+}
+
+// handleMessage applies a message from the client. It returns false if the
+// client must be disconnected.
+func (sc *serverClient) handleMessage(tempPacket message.IMessage) bool {
+	s := sc.server
+	switch tempPacket.GetType() {
+	case message.TypeClientHello:
+		// Step 1: Client sends Client Hello
+		msg := tempPacket.(*message.ClientHello)
+		if sc.identity != "" {
+			s.logger.Warnf("server: %s sent a second ClientHello", sc.identity)
+			return false
+		}
+		if msg.GetProtoRev() != protocolRev3 {
+			// nothing else has been queued yet, so the reply can be written
+			// directly before the connection is closed
+			sc.conn.Write(message.ProtoUnsupportedFromItems(protocolRev3).CompressToBytes())
+			return false
+		}
+		sc.identity = msg.GetIdentity()
+		if sc.identity == "" {
+			sc.identity = sc.conn.RemoteAddr().String()
+		}
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if s.closed {
+			return false
+		}
+		// Step 2: Server replies to ClientHello with ServerHello
+		var flags byte
+		if s.seen[sc.identity] {
+			flags = 0x01
+		}
+		s.seen[sc.identity] = true
+		hello, err := message.ServerHelloFromItems(flags, codec.EncodeString(s.identity))
+		if err != nil {
+			s.logger.Errorf("server: %s", err)
+			return false
+		}
+		// the queue fits the whole handshake, so a server holding many
+		// entries does not mistake a new client for a slow one
+		sc.outgoing = make(chan message.IMessage, serverQueueSize+len(s.entries)+2)
+		go sc.processOutgoingQueue()
+		sc.send(hello)
+		// Step 3: Server sends EntryAssign messages for each entry
+		for _, e := range s.entries {
+			sc.send(message.EntryAssignFromEntry(e))
+		}
+		// Step 4: The Server sends a Server Hello Complete message.
+		sc.send(message.ServerHelloCompleteFromItems())
+		s.clients[sc] = true
+
+	case message.TypeClientHelloComplete:
+		// Step 6: The Client is done sending the entries the server did not know about
+
+	case message.TypeKeepAlive:
+		// can be safely ignored
+
+	case message.TypeEntryAssign:
+		msg := tempPacket.(*message.EntryAssign)
+		newEntry := msg.GetEntry()
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if newEntry.GetRawID() != unassignedID {
+			s.logger.Warnf("server: %s tried to assign an entry ID", sc.identity)
+			return true
+		}
+		if _, exists := s.entries[newEntry.GetName()]; exists {
+			// most likely a duplicate, sent before our EntryAssign reached the client
+			return true
+		}
+		if err := s.assignEntry(newEntry); err != nil {
+			s.logger.Warnf("server: could not assign %s from %s: %s", newEntry.GetName(), sc.identity, err)
+			return true
+		}
+		if newEntry.GetType() == entry.TypeRPCDef {
+			s.rpcOwners[newEntry.GetName()] = sc
+		}
+
+	case message.TypeEntryUpdate:
+		msg := tempPacket.(*message.EntryUpdate)
+		up := msg.GetUpdate()
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		e, ok := s.entryByID(up.GetRawID())
+		if !ok || e.GetType() != up.GetType() {
+			return true
+		}
+		// only strictly newer values are applied; when the comparison is
+		// undefined the server wins
+		if !util.SequenceGreater(up.GetSequence(), e.GetSequence()) {
+			return true
+		}
+		updated, err := copyEntry(e, up.GetSequence(), e.GetFlags(), up.GetValueUnsafe())
+		if err != nil {
+			s.logger.Warnf("server: ignoring update to %s: %s", e.GetName(), err)
+			return true
+		}
+		s.entries[updated.GetName()] = updated
+		s.broadcast(msg, sc)
+
+	case message.TypeEntryFlagUpdate:
+		msg := tempPacket.(*message.EntryFlagUpdate)
+		up := msg.GetFlagUpdate()
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		e, ok := s.entryByID(up.ID)
+		if !ok {
+			return true
+		}
+		updated, err := copyEntry(e, e.GetSequence(), up.GetFlags(), e.GetValue())
+		if err != nil {
+			s.logger.Warnf("server: ignoring flag update to %s: %s", e.GetName(), err)
+			return true
+		}
+		s.entries[updated.GetName()] = updated
+		s.broadcast(msg, sc)
+
+	case message.TypeEntryDelete:
+		msg := tempPacket.(*message.EntryDelete)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		e, ok := s.entryByID(msg.GetID())
+		if !ok {
+			return true
+		}
+		s.deleteEntry(e)
+		s.broadcast(msg, sc)
+
+	case message.TypeClearAllEntries:
+		msg := tempPacket.(*message.ClearAllEntries)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for _, e := range s.entries {
+			s.deleteEntry(e)
+		}
+		s.broadcast(msg, sc)
+
+	case message.TypeRPCExec:
+		msg := tempPacket.(*message.RPCExec)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.handleRPCExec(sc, msg)
+
+	case message.TypeRPCResponse:
+		// only expected from clients serving a procedure we forwarded a call to
+		msg := tempPacket.(*message.RPCResponse)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		call := rpcCall{id: msg.GetID(), uniqueID: msg.GetUniqueID()}
+		forward, ok := s.rpcForwards[call]
+		if !ok {
+			return true
+		}
+		delete(s.rpcForwards, call)
+		forward.caller.send(message.RPCResponseFromItems(msg.GetID(), forward.uniqueID, msg.GetResults()))
+
+	default:
+		// ServerHello, ServerHelloComplete and ProtoUnsupported are only sent by servers
+		s.logger.Warnf("server: unexpected %s from %s", tempPacket.GetType(), sc.identity)
+		return false
+	}
+	return true
+}
+
+// handleRPCExec runs a procedure served by the server, or forwards the call
+// to the client serving it. The server's mutex must be held.
+func (s *Server) handleRPCExec(caller *serverClient, exec *message.RPCExec) {
+	e, ok := s.entryByID(exec.GetID())
+	if !ok || e.GetType() != entry.TypeRPCDef {
+		s.logger.Warnf("server: call to unknown procedure %#x", exec.GetID())
+		return
+	}
+	if handler, ok := s.rpcHandlers[e.GetName()]; ok {
+		definition := e.GetValue().(entry.RPCDefinition)
+		go func() {
+			response := executeRPC(s.logger, definition, handler, exec)
+			if response != nil {
+				caller.send(response)
+			}
+		}()
+		return
+	}
+	owner, ok := s.rpcOwners[e.GetName()]
+	if !ok {
+		s.logger.Warnf("server: nobody is serving %s", e.GetName())
+		return
+	}
+	// unique IDs are only unique per caller, so the call is given a new one
+	call := rpcCall{id: exec.GetID()}
+	s.rpcUniqueID++
+	binary.BigEndian.PutUint16(call.uniqueID[:], s.rpcUniqueID)
+	s.rpcForwards[call] = rpcForward{caller: caller, uniqueID: exec.GetUniqueID()}
+	owner.send(message.RPCExecFromItems(call.id, call.uniqueID, exec.GetParams()))
+}
@@ -0,0 +1,214 @@
+package frcntgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/techplexengineer/frc-networktables-go/codec"
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/message"
+)
+
+// quietLogger drops the log messages of the clients and servers under test
+var quietLogger = NewLogger(nil, LevelNone)
+
+// startServer serves a new server on a free local port, which is returned
+func startServer(t *testing.T, options ...ServerOption) (*Server, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer("test server", append([]ServerOption{WithServerLogger(quietLogger)}, options...)...)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return server, strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// startClient connects a new client to the local port and waits for it to
+// be in sync with the server
+func startClient(t *testing.T, port string, options ...ClientOption) *Client {
+	t.Helper()
+	client, err := NewClient("127.0.0.1", port, append([]ClientOption{WithLogger(quietLogger)}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.WaitForSync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// eventually fails the test if the condition does not become true soon
+func eventually(t *testing.T, condition func() bool, format string, args ...interface{}) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf(format, args...)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerHandshakeWithManyEntries(t *testing.T) {
+	server, port := startServer(t)
+	const count = 3 * serverQueueSize
+	for i := 0; i < count; i++ {
+		if err := server.SetValue(fmt.Sprintf("/entry/%d", i), entry.TypeDouble, float64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := startClient(t, port)
+	if keys := client.GetKeys(""); len(keys) != count {
+		t.Fatalf("client has %d entries, want %d", len(keys), count)
+	}
+	if status := client.GetStatus(); status != ClientInSync {
+		t.Fatalf("client is %v after the handshake", status)
+	}
+}
+
+func TestServerGetEntryDuringUpdates(t *testing.T) {
+	server, port := startServer(t)
+	client := startClient(t, port, WithFlushPeriod(0))
+	if err := client.SetDouble("/speed", 0); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool { return server.GetEntry("/speed") != nil }, "the server never got /speed")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 500; i++ {
+			client.SetDouble("/speed", float64(i))
+			client.SetPersistent("/speed", i%2 == 0)
+		}
+	}()
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		if e := server.GetEntry("/speed"); e != nil {
+			e.GetValue()
+			e.IsPersistent()
+		}
+	}
+	eventually(t, func() bool { return server.GetEntry("/speed").GetValue() == float64(500) }, "the server never got the last update")
+
+	// changing the returned copy leaves the server's entry alone
+	server.GetEntry("/speed").SetValue(float64(-1))
+	if value := server.GetEntry("/speed").GetValue(); value != float64(500) {
+		t.Fatalf("server entry changed to %v through a copy", value)
+	}
+}
+
+func TestServerRejectsRev2Client(t *testing.T) {
+	_, port := startServer(t)
+	conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// a NetworkTables 2.0 Client Hello has no identity after the revision
+	if _, err := conn.Write([]byte{0x01, 0x02, 0x00}); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x02, 0x03, 0x00}
+	if !bytes.Equal(reply, want) {
+		t.Fatalf("server replied % x, want % x", reply, want)
+	}
+}
+
+func TestServerAssignsSequenceNumbers(t *testing.T) {
+	server, port := startServer(t)
+	conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	hello, err := message.ClientHelloFromItems(protocolRev3, codec.EncodeString("raw client"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write(hello.CompressToBytes()); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	decoder := message.NewDecoder(conn)
+	defer decoder.Release()
+	for {
+		msg, err := decoder.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.GetType() == message.TypeServerHelloComplete {
+			break
+		}
+	}
+
+	// a client may send anything as the sequence number of a new entry
+	created := entry.DoubleFromValue("/speed", unassignedID, [2]byte{0x80, 0x00}, 0x00, 1.5)
+	data := append(message.ClientHelloCompleteFromItems().CompressToBytes(), message.EntryAssignFromEntry(created).CompressToBytes()...)
+	if _, err := conn.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool { return server.GetEntry("/speed") != nil }, "the server never assigned the entry")
+	if sequence := server.GetEntry("/speed").GetSequence(); sequence != 0 {
+		t.Fatalf("server assigned sequence number %#04x, want 0", sequence)
+	}
+
+	// the server's own updates follow on from it
+	if err := server.SetValue("/speed", entry.TypeDouble, 2.5); err != nil {
+		t.Fatal(err)
+	}
+	client := startClient(t, port)
+	if value, _ := client.GetDouble("/speed"); value != 2.5 {
+		t.Fatalf("client got %v, want the server's update", value)
+	}
+}
+
+func TestServerRestartWithSeveralClients(t *testing.T) {
+	server, port := startServer(t)
+	reconnect := WithReconnect(10*time.Millisecond, 100*time.Millisecond)
+	first := startClient(t, port, reconnect)
+	second := startClient(t, port, reconnect)
+	first.SetDouble("/first", 1)
+	second.SetDouble("/second", 2)
+	first.Flush()
+	second.Flush()
+	eventually(t, func() bool {
+		return server.GetEntry("/first") != nil && server.GetEntry("/second") != nil
+	}, "the server never got both values")
+
+	// the new server starts out with defaults for both entries, which each
+	// client must replace with its own value once it notices the restart
+	server.Close()
+	restarted := NewServer("test server", WithServerLogger(quietLogger))
+	restarted.SetValue("/first", entry.TypeDouble, float64(0))
+	restarted.SetValue("/second", entry.TypeDouble, float64(0))
+	listener, err := net.Listen("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go restarted.Serve(listener)
+	defer restarted.Close()
+	eventually(t, func() bool {
+		return restarted.GetEntry("/first").GetValue() == float64(1) &&
+			restarted.GetEntry("/second").GetValue() == float64(2)
+	}, "the restarted server kept its defaults")
+}
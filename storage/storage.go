@@ -0,0 +1,365 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+const (
+	// header is the first line of every persistent file
+	header = "[NetworkTables Storage 3.0]"
+	// maxLineLength is the longest line accepted when reading a persistent file
+	maxLineLength = 16 * 1024 * 1024
+)
+
+// Entry is a single entry saved in a persistent file
+type Entry struct {
+	Name  string
+	Type  entry.EntryType
+	Value interface{}
+}
+
+// Load reads the persistent file at the given path
+func Load(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Save writes the entries to the persistent file at the given path. The file
+// is replaced atomically so a crash while saving never leaves it half written.
+func Save(path string, entries []Entry) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	err = Write(temp, entries)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// Read parses entries in the "NetworkTables Storage 3.0" format
+func Read(reader io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(reader)
+	// raw values and long arrays can exceed the default line limit
+	scanner.Buffer(nil, maxLineLength)
+	lineNumber := 0
+	foundHeader := false
+	entries := []Entry{}
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if !foundHeader {
+			if line != header {
+				return nil, fmt.Errorf("storage: line %d: missing %s header", lineNumber, header)
+			}
+			foundHeader = true
+			continue
+		}
+		parsed, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("storage: line %d: %s", lineNumber, err)
+		}
+		entries = append(entries, parsed)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !foundHeader {
+		return nil, fmt.Errorf("storage: missing %s header", header)
+	}
+	return entries, nil
+}
+
+// Write writes entries in the "NetworkTables Storage 3.0" format, sorted by name
+func Write(writer io.Writer, entries []Entry) error {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	buffered := bufio.NewWriter(writer)
+	buffered.WriteString(header + "\n")
+	for _, e := range sorted {
+		line, err := formatLine(e)
+		if err != nil {
+			return err
+		}
+		buffered.WriteString(line + "\n")
+	}
+	return buffered.Flush()
+}
+
+func parseLine(line string) (Entry, error) {
+	var parsed Entry
+	typeName, rest := splitWord(line)
+	if typeName == "array" {
+		var elementName string
+		elementName, rest = splitWord(rest)
+		typeName += " " + elementName
+	}
+	switch typeName {
+	case "boolean":
+		parsed.Type = entry.TypeBoolean
+	case "double":
+		parsed.Type = entry.TypeDouble
+	case "string":
+		parsed.Type = entry.TypeString
+	case "raw":
+		parsed.Type = entry.TypeRaw
+	case "array boolean":
+		parsed.Type = entry.TypeBooleanArr
+	case "array double":
+		parsed.Type = entry.TypeDoubleArr
+	case "array string":
+		parsed.Type = entry.TypeStringArr
+	default:
+		return parsed, fmt.Errorf("unknown type %q", typeName)
+	}
+
+	name, rest, err := readQuoted(rest)
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Name = name
+	if !strings.HasPrefix(rest, "=") {
+		return parsed, errors.New("expected '=' after the name")
+	}
+	rest = rest[1:]
+
+	switch parsed.Type {
+	case entry.TypeBoolean:
+		parsed.Value, err = parseBoolean(rest)
+	case entry.TypeDouble:
+		parsed.Value, err = strconv.ParseFloat(rest, 64)
+	case entry.TypeString:
+		var value string
+		value, rest, err = readQuoted(rest)
+		if err == nil && rest != "" {
+			err = errors.New("unexpected data after the string")
+		}
+		parsed.Value = value
+	case entry.TypeRaw:
+		parsed.Value, err = base64.StdEncoding.DecodeString(rest)
+	case entry.TypeBooleanArr:
+		value := []bool{}
+		for _, element := range splitArray(rest) {
+			var parsedElement bool
+			if parsedElement, err = parseBoolean(element); err != nil {
+				break
+			}
+			value = append(value, parsedElement)
+		}
+		parsed.Value = value
+	case entry.TypeDoubleArr:
+		value := []float64{}
+		for _, element := range splitArray(rest) {
+			var parsedElement float64
+			if parsedElement, err = strconv.ParseFloat(element, 64); err != nil {
+				break
+			}
+			value = append(value, parsedElement)
+		}
+		parsed.Value = value
+	case entry.TypeStringArr:
+		value := []string{}
+		for rest != "" {
+			var element string
+			if element, rest, err = readQuoted(rest); err != nil {
+				break
+			}
+			value = append(value, element)
+			if rest != "" {
+				if rest[0] != ',' {
+					err = errors.New("expected ',' between strings")
+					break
+				}
+				rest = strings.TrimSpace(rest[1:])
+			}
+		}
+		parsed.Value = value
+	}
+	return parsed, err
+}
+
+func formatLine(e Entry) (string, error) {
+	var typeName, value string
+	var ok bool
+	switch e.Type {
+	case entry.TypeBoolean:
+		var val bool
+		if val, ok = e.Value.(bool); ok {
+			typeName, value = "boolean", strconv.FormatBool(val)
+		}
+	case entry.TypeDouble:
+		var val float64
+		if val, ok = e.Value.(float64); ok {
+			typeName, value = "double", strconv.FormatFloat(val, 'g', -1, 64)
+		}
+	case entry.TypeString:
+		var val string
+		if val, ok = e.Value.(string); ok {
+			typeName, value = "string", quote(val)
+		}
+	case entry.TypeRaw:
+		var val []byte
+		if val, ok = e.Value.([]byte); ok {
+			typeName, value = "raw", base64.StdEncoding.EncodeToString(val)
+		}
+	case entry.TypeBooleanArr:
+		var val []bool
+		if val, ok = e.Value.([]bool); ok {
+			elements := make([]string, len(val))
+			for index, element := range val {
+				elements[index] = strconv.FormatBool(element)
+			}
+			typeName, value = "array boolean", strings.Join(elements, ",")
+		}
+	case entry.TypeDoubleArr:
+		var val []float64
+		if val, ok = e.Value.([]float64); ok {
+			elements := make([]string, len(val))
+			for index, element := range val {
+				elements[index] = strconv.FormatFloat(element, 'g', -1, 64)
+			}
+			typeName, value = "array double", strings.Join(elements, ",")
+		}
+	case entry.TypeStringArr:
+		var val []string
+		if val, ok = e.Value.([]string); ok {
+			elements := make([]string, len(val))
+			for index, element := range val {
+				elements[index] = quote(element)
+			}
+			typeName, value = "array string", strings.Join(elements, ",")
+		}
+	default:
+		return "", fmt.Errorf("storage: %s entries can not be persisted", e.Type)
+	}
+	if !ok {
+		return "", fmt.Errorf("storage: %T is not a valid %s value for %s", e.Value, e.Type, e.Name)
+	}
+	return typeName + " " + quote(e.Name) + "=" + value, nil
+}
+
+// splitWord returns the first space separated word and the rest of the line
+func splitWord(line string) (string, string) {
+	index := strings.IndexByte(line, ' ')
+	if index < 0 {
+		return line, ""
+	}
+	return line[:index], strings.TrimSpace(line[index+1:])
+}
+
+// splitArray splits a comma separated list of unquoted values
+func splitArray(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	elements := strings.Split(value, ",")
+	for index := range elements {
+		elements[index] = strings.TrimSpace(elements[index])
+	}
+	return elements
+}
+
+func parseBoolean(value string) (bool, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q", value)
+	}
+}
+
+// readQuoted reads a double quoted, escaped string from the start of the line
+// and returns it along with the rest of the line
+func readQuoted(line string) (string, string, error) {
+	if !strings.HasPrefix(line, "\"") {
+		return "", line, errors.New("expected '\"'")
+	}
+	var unquoted strings.Builder
+	for index := 1; index < len(line); index++ {
+		char := line[index]
+		switch char {
+		case '"':
+			return unquoted.String(), strings.TrimSpace(line[index+1:]), nil
+		case '\\':
+			index++
+			if index >= len(line) {
+				return "", "", errors.New("unterminated escape sequence")
+			}
+			switch line[index] {
+			case 't':
+				unquoted.WriteByte('\t')
+			case 'n':
+				unquoted.WriteByte('\n')
+			case 'x':
+				if index+2 >= len(line) {
+					return "", "", errors.New("unterminated hex escape")
+				}
+				hex, err := strconv.ParseUint(line[index+1:index+3], 16, 8)
+				if err != nil {
+					return "", "", fmt.Errorf("invalid hex escape %q", line[index+1:index+3])
+				}
+				unquoted.WriteByte(byte(hex))
+				index += 2
+			default:
+				// \\ and \" along with any unknown escape stand for the character itself
+				unquoted.WriteByte(line[index])
+			}
+		default:
+			unquoted.WriteByte(char)
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+// quote escapes a string the same way ntcore does when writing a persistent file
+func quote(value string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for index := 0; index < len(value); index++ {
+		char := value[index]
+		switch {
+		case char == '\\':
+			quoted.WriteString("\\\\")
+		case char == '"':
+			quoted.WriteString("\\\"")
+		case char == '\t':
+			quoted.WriteString("\\t")
+		case char == '\n':
+			quoted.WriteString("\\n")
+		case char < 0x20 || char >= 0x7f:
+			fmt.Fprintf(&quoted, "\\x%02X", char)
+		default:
+			quoted.WriteByte(char)
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
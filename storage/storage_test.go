@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+func TestLoadTestTypes(t *testing.T) {
+	entries, err := Load(filepath.Join("..", "testTypes.ini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{"/bool", entry.TypeBoolean, false},
+		{"/boolArr", entry.TypeBooleanArr, []bool{false, true, false}},
+		{"/doubleArr", entry.TypeDoubleArr, []float64{0, 1, 2, 3}},
+		{"/number", entry.TypeDouble, 0.0},
+		{"/string", entry.TypeString, "test"},
+		{"/stringArr", entry.TypeStringArr, []string{"first", "second"}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Fatalf("loaded %v, want %v", entries, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{"/boolean", entry.TypeBoolean, true},
+		{"/double", entry.TypeDouble, -1.25},
+		{"/quoted \"name\"", entry.TypeString, "say \"hi\""},
+		{"/escaped\\name", entry.TypeString, "back\\slash\tand tab"},
+		{"/control", entry.TypeString, "bell\x07 and \xff"},
+		{"/newline", entry.TypeString, "first\nsecond"},
+		{"/raw", entry.TypeRaw, []byte{0x00, 0xff, 0x10, 0x80}},
+		{"/empty raw", entry.TypeRaw, []byte{}},
+		{"/empty booleans", entry.TypeBooleanArr, []bool{}},
+		{"/empty doubles", entry.TypeDoubleArr, []float64{}},
+		{"/empty strings", entry.TypeStringArr, []string{}},
+		{"/doubles", entry.TypeDoubleArr, []float64{1, 2.5, -3e10}},
+		{"/strings", entry.TypeStringArr, []string{"a,b", "", "\"c\",d", "e\\,"}},
+	}
+	var written bytes.Buffer
+	if err := Write(&written, entries); err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(&written)
+	if err != nil {
+		t.Fatalf("%s in\n%s", err, written.String())
+	}
+	if len(read) != len(entries) {
+		t.Fatalf("read %d entries, want %d", len(read), len(entries))
+	}
+	byName := map[string]Entry{}
+	for _, e := range read {
+		byName[e.Name] = e
+	}
+	for _, e := range entries {
+		if got := byName[e.Name]; !reflect.DeepEqual(got, e) {
+			t.Errorf("read %#v, want %#v", got, e)
+		}
+	}
+}
+
+func TestWriteEscapes(t *testing.T) {
+	var written bytes.Buffer
+	err := Write(&written, []Entry{{"/a\"b", entry.TypeString, "\\\t\x01"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := header + "\n" + `string "/a\"b"="\\\t\x01"` + "\n"
+	if written.String() != want {
+		t.Fatalf("wrote %q, want %q", written.String(), want)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "networktables.ini")
+	entries := []Entry{{"/speed", entry.TypeDouble, 2.0}}
+	if err := Save(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, entries) {
+		t.Fatalf("loaded %v, want %v", loaded, entries)
+	}
+	if matches, _ := filepath.Glob(path + ".tmp*"); len(matches) != 0 {
+		t.Fatalf("Save left %v behind", matches)
+	}
+}
+
+func TestReadRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"empty file", ""},
+		{"missing header", `double "/speed"=1`},
+		{"wrong header", "[NetworkTables Storage 2.0]"},
+		{"unknown type", `integer "/speed"=1`},
+		{"unknown array type", `array raw "/speed"=AA==`},
+		{"unquoted name", `double /speed=1`},
+		{"unterminated name", `double "/speed=1`},
+		{"missing equals", `double "/speed" 1`},
+		{"invalid boolean", `boolean "/enabled"=yes`},
+		{"invalid double", `double "/speed"=fast`},
+		{"unquoted string", `string "/name"=robot`},
+		{"data after string", `string "/name"="robot" extra`},
+		{"invalid base64", `raw "/raw"=!!!`},
+		{"invalid boolean element", `array boolean "/flags"=true,maybe`},
+		{"invalid double element", `array double "/values"=1,,2`},
+		{"missing comma", `array string "/names"="a" "b"`},
+		{"invalid hex escape", `string "/name"="\xZZ"`},
+		{"unterminated escape", `string "/name"="\`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := test.file
+			if !strings.HasPrefix(test.name, "empty") && !strings.Contains(test.name, "header") {
+				file = header + "\n" + file
+			}
+			if entries, err := Read(strings.NewReader(file)); err == nil {
+				t.Fatalf("read %v from %q", entries, file)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package frcntgo
+
+import (
+	"sync"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+// entryStore holds the entries by name and by ID. Readers look entries up
+// without locking, while writers take turns so that both maps are changed
+// together. Entries in the store are shared with readers, so they must never
+// be changed in place.
+type entryStore struct {
+	mutex sync.Mutex
+	// byName maps names to entries, and byID the IDs the server assigned
+	// to entries. Entries waiting on the server all share the same
+	// placeholder ID, so they are only found by name.
+	byName sync.Map
+	byID   sync.Map
+}
+
+func newEntryStore() *entryStore {
+	return &entryStore{}
+}
+
+// get returns the entry with the name
+func (store *entryStore) get(name string) (entry.IEntry, bool) {
+	e, ok := store.byName.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return e.(entry.IEntry), true
+}
+
+// getByID returns the entry the server assigned the ID to
+func (store *entryStore) getByID(id [2]byte) (entry.IEntry, bool) {
+	e, ok := store.byID.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return e.(entry.IEntry), true
+}
+
+// all returns every entry by name in a map of the caller's own
+func (store *entryStore) all() map[string]entry.IEntry {
+	entries := map[string]entry.IEntry{}
+	store.byName.Range(func(name, e interface{}) bool {
+		entries[name.(string)] = e.(entry.IEntry)
+		return true
+	})
+	return entries
+}
+
+// put adds the entry, replacing any entry with the same name
+func (store *entryStore) put(e entry.IEntry) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if previous, ok := store.get(e.GetName()); ok {
+		store.forgetID(previous)
+	}
+	store.byName.Store(e.GetName(), e)
+	if !entry.IsPending(e) {
+		store.byID.Store(e.GetRawID(), e)
+	}
+}
+
+// delete removes the entry with the name
+func (store *entryStore) delete(name string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if previous, ok := store.get(name); ok {
+		store.forgetID(previous)
+		store.byName.Delete(name)
+	}
+}
+
+// clear removes every entry
+func (store *entryStore) clear() {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.byName.Range(func(name, _ interface{}) bool {
+		store.byName.Delete(name)
+		return true
+	})
+	store.byID.Range(func(id, _ interface{}) bool {
+		store.byID.Delete(id)
+		return true
+	})
+}
+
+// forgetID removes the entry from the IDs, unless the server has given its
+// ID to another entry since. The store's mutex must be held.
+func (store *entryStore) forgetID(e entry.IEntry) {
+	if entry.IsPending(e) {
+		return
+	}
+	if current, ok := store.getByID(e.GetRawID()); ok && current.GetName() == e.GetName() {
+		store.byID.Delete(e.GetRawID())
+	}
+}
@@ -0,0 +1,80 @@
+package frcntgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+// assignedDouble returns a double entry the server assigned the ID to
+func assignedDouble(name string, id uint16, value float64) entry.IEntry {
+	var rawID [2]byte
+	binary.BigEndian.PutUint16(rawID[:], id)
+	return entry.DoubleFromValue(name, rawID, [2]byte{}, 0x00, value)
+}
+
+func TestEntryStore(t *testing.T) {
+	store := newEntryStore()
+	pending := entry.NewDouble("/speed", 1)
+	store.put(pending)
+	if e, ok := store.get("/speed"); !ok || e != pending {
+		t.Fatal("pending entry not found by name")
+	}
+	before := store.all()
+
+	assigned := assignedDouble("/speed", 7, 2)
+	store.put(assigned)
+	if e, ok := store.getByID(assigned.GetRawID()); !ok || e != assigned {
+		t.Fatal("assigned entry not found by ID")
+	}
+	updated := assignedDouble("/speed", 7, 3)
+	store.put(updated)
+	if e, _ := store.getByID(updated.GetRawID()); e != updated {
+		t.Fatal("updated entry not found by ID")
+	}
+	if e, _ := store.get("/speed"); e != updated {
+		t.Fatal("updated entry not found by name")
+	}
+	if before["/speed"] != pending {
+		t.Fatal("a map returned earlier changed")
+	}
+
+	reassigned := assignedDouble("/speed", 8, 4)
+	store.put(reassigned)
+	if _, ok := store.getByID(updated.GetRawID()); ok {
+		t.Fatal("entry still found by its old ID")
+	}
+	if e, _ := store.getByID(reassigned.GetRawID()); e != reassigned {
+		t.Fatal("reassigned entry not found by its new ID")
+	}
+
+	store.delete("/speed")
+	if _, ok := store.get("/speed"); ok {
+		t.Fatal("deleted entry found by name")
+	}
+	if _, ok := store.getByID(reassigned.GetRawID()); ok {
+		t.Fatal("deleted entry found by ID")
+	}
+}
+
+func BenchmarkEntryStoreUpdate(b *testing.B) {
+	for _, count := range []int{10, 1000} {
+		b.Run(fmt.Sprintf("%d entries", count), func(b *testing.B) {
+			store := newEntryStore()
+			for i := 0; i < count; i++ {
+				store.put(assignedDouble(fmt.Sprintf("/entry/%d", i), uint16(i), 0))
+			}
+			updates := []entry.IEntry{
+				assignedDouble("/entry/0", 0, 1),
+				assignedDouble("/entry/0", 0, 2),
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				store.put(updates[i%2])
+			}
+		})
+	}
+}
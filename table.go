@@ -1,12 +1,291 @@
 package frcntgo
 
-//// Table represents a collection of data at a specified depth in the network table
-//type Table struct {
-//	path   string
-//	client *Client
-//}
-//
-//// GetBoolean calls GetBoolean on the client object
-//func (table *Table) GetBoolean(key string) bool {
-//	return table.client.GetBoolean(key)
-//}
+import (
+	"sort"
+	"strings"
+)
+
+// pathSeparator separates the tables and keys that make up an entry's name
+const pathSeparator = "/"
+
+// Table represents a collection of data at a specified depth in the network table
+type Table struct {
+	path   string
+	client *Client
+}
+
+// GetTable returns the table at the specified path, such as "/SmartDashboard".
+// Tables exist as long as an entry does below them, so this never fails.
+func (c *Client) GetTable(path string) *Table {
+	path = strings.Trim(path, pathSeparator)
+	if path != "" {
+		path = pathSeparator + path
+	}
+	return &Table{path: path, client: c}
+}
+
+// GetPath returns the full path of the table
+func (table *Table) GetPath() string {
+	if table.path == "" {
+		return pathSeparator
+	}
+	return table.path
+}
+
+// GetSubTable returns the table at the path relative to this one
+func (table *Table) GetSubTable(path string) *Table {
+	return table.client.GetTable(table.path + pathSeparator + path)
+}
+
+// key returns the full name of the entry at the key relative to this table
+func (table *Table) key(key string) string {
+	return table.path + pathSeparator + strings.TrimPrefix(key, pathSeparator)
+}
+
+// relative returns the name of an entry relative to this table, and whether
+// the entry is below this table at all
+func (table *Table) relative(name string) (string, bool) {
+	prefix := table.path + pathSeparator
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	return name[len(prefix):], true
+}
+
+// GetKeys returns the keys of the entries directly in this table
+func (table *Table) GetKeys() []string {
+	keys := []string{}
+	for _, name := range table.client.GetKeys(table.path + pathSeparator) {
+		if key, ok := table.relative(name); ok && !strings.Contains(key, pathSeparator) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetSubTables returns the names of the tables directly below this table
+func (table *Table) GetSubTables() []string {
+	seen := map[string]bool{}
+	subTables := []string{}
+	for _, name := range table.client.GetKeys(table.path + pathSeparator) {
+		key, ok := table.relative(name)
+		if !ok {
+			continue
+		}
+		index := strings.Index(key, pathSeparator)
+		if index <= 0 || seen[key[:index]] {
+			continue
+		}
+		seen[key[:index]] = true
+		subTables = append(subTables, key[:index])
+	}
+	sort.Strings(subTables)
+	return subTables
+}
+
+// ContainsKey determines whether the key is in this table
+func (table *Table) ContainsKey(key string) bool {
+	return table.client.ContainsKey(table.key(key))
+}
+
+// ContainsSubTable determines whether the table has a sub table with the name
+func (table *Table) ContainsSubTable(name string) bool {
+	return len(table.client.GetKeys(table.key(name)+pathSeparator)) > 0
+}
+
+// GetEntry returns the value of the entry at the key, or nil if there is none
+func (table *Table) GetEntry(key string) interface{} {
+	if !table.ContainsKey(key) {
+		return nil
+	}
+	return table.client.GetEntry(table.key(key))
+}
+
+// GetBoolean fetches a boolean at the key in this table
+func (table *Table) GetBoolean(key string) (bool, error) {
+	return table.client.GetBoolean(table.key(key))
+}
+
+// GetDouble fetches a double at the key in this table
+func (table *Table) GetDouble(key string) (float64, error) {
+	return table.client.GetDouble(table.key(key))
+}
+
+// GetString fetches a string at the key in this table
+func (table *Table) GetString(key string) (string, error) {
+	return table.client.GetString(table.key(key))
+}
+
+// GetRaw fetches a raw value at the key in this table
+func (table *Table) GetRaw(key string) ([]byte, error) {
+	return table.client.GetRaw(table.key(key))
+}
+
+// GetBooleanArray fetches a boolean array at the key in this table
+func (table *Table) GetBooleanArray(key string) ([]bool, error) {
+	return table.client.GetBooleanArray(table.key(key))
+}
+
+// GetDoubleArray fetches a double array at the key in this table
+func (table *Table) GetDoubleArray(key string) ([]float64, error) {
+	return table.client.GetDoubleArray(table.key(key))
+}
+
+// GetStringArray fetches a string array at the key in this table
+func (table *Table) GetStringArray(key string) ([]string, error) {
+	return table.client.GetStringArray(table.key(key))
+}
+
+// GetBooleanOrDefault fetches a boolean at the key in this table, or returns the
+// default value if there is none there
+func (table *Table) GetBooleanOrDefault(key string, defaultValue bool) bool {
+	return table.client.GetBooleanOrDefault(table.key(key), defaultValue)
+}
+
+// GetDoubleOrDefault fetches a double at the key in this table, or returns the
+// default value if there is none there
+func (table *Table) GetDoubleOrDefault(key string, defaultValue float64) float64 {
+	return table.client.GetDoubleOrDefault(table.key(key), defaultValue)
+}
+
+// GetStringOrDefault fetches a string at the key in this table, or returns the
+// default value if there is none there
+func (table *Table) GetStringOrDefault(key string, defaultValue string) string {
+	return table.client.GetStringOrDefault(table.key(key), defaultValue)
+}
+
+// GetRawOrDefault fetches a raw value at the key in this table, or returns the
+// default value if there is none there
+func (table *Table) GetRawOrDefault(key string, defaultValue []byte) []byte {
+	return table.client.GetRawOrDefault(table.key(key), defaultValue)
+}
+
+// GetBooleanArrayOrDefault fetches a boolean array at the key in this table, or returns the
+// default value if there is none there
+func (table *Table) GetBooleanArrayOrDefault(key string, defaultValue []bool) []bool {
+	return table.client.GetBooleanArrayOrDefault(table.key(key), defaultValue)
+}
+
+// GetDoubleArrayOrDefault fetches a double array at the key in this table, or returns the
+// default value if there is none there
+func (table *Table) GetDoubleArrayOrDefault(key string, defaultValue []float64) []float64 {
+	return table.client.GetDoubleArrayOrDefault(table.key(key), defaultValue)
+}
+
+// GetStringArrayOrDefault fetches a string array at the key in this table, or returns the
+// default value if there is none there
+func (table *Table) GetStringArrayOrDefault(key string, defaultValue []string) []string {
+	return table.client.GetStringArrayOrDefault(table.key(key), defaultValue)
+}
+
+// PutBoolean creates a boolean entry at the key in this table
+func (table *Table) PutBoolean(key string, value bool) error {
+	return table.client.PutBoolean(table.key(key), value)
+}
+
+// PutDouble creates a double entry at the key in this table
+func (table *Table) PutDouble(key string, value float64) error {
+	return table.client.PutDouble(table.key(key), value)
+}
+
+// PutString creates a string entry at the key in this table
+func (table *Table) PutString(key string, value string) error {
+	return table.client.PutString(table.key(key), value)
+}
+
+// PutRaw creates a raw entry at the key in this table
+func (table *Table) PutRaw(key string, value []byte) error {
+	return table.client.PutRaw(table.key(key), value)
+}
+
+// PutBooleanArray creates a boolean array entry at the key in this table
+func (table *Table) PutBooleanArray(key string, value []bool) error {
+	return table.client.PutBooleanArray(table.key(key), value)
+}
+
+// PutDoubleArray creates a double array entry at the key in this table
+func (table *Table) PutDoubleArray(key string, value []float64) error {
+	return table.client.PutDoubleArray(table.key(key), value)
+}
+
+// PutStringArray creates a string array entry at the key in this table
+func (table *Table) PutStringArray(key string, value []string) error {
+	return table.client.PutStringArray(table.key(key), value)
+}
+
+// SetBoolean updates the boolean at the key in this table, creating it if needed
+func (table *Table) SetBoolean(key string, value bool) error {
+	return table.client.SetBoolean(table.key(key), value)
+}
+
+// SetDouble updates the double at the key in this table, creating it if needed
+func (table *Table) SetDouble(key string, value float64) error {
+	return table.client.SetDouble(table.key(key), value)
+}
+
+// SetString updates the string at the key in this table, creating it if needed
+func (table *Table) SetString(key string, value string) error {
+	return table.client.SetString(table.key(key), value)
+}
+
+// SetRaw updates the raw value at the key in this table, creating it if needed
+func (table *Table) SetRaw(key string, value []byte) error {
+	return table.client.SetRaw(table.key(key), value)
+}
+
+// SetBooleanArray updates the boolean array at the key in this table, creating it if needed
+func (table *Table) SetBooleanArray(key string, value []bool) error {
+	return table.client.SetBooleanArray(table.key(key), value)
+}
+
+// SetDoubleArray updates the double array at the key in this table, creating it if needed
+func (table *Table) SetDoubleArray(key string, value []float64) error {
+	return table.client.SetDoubleArray(table.key(key), value)
+}
+
+// SetStringArray updates the string array at the key in this table, creating it if needed
+func (table *Table) SetStringArray(key string, value []string) error {
+	return table.client.SetStringArray(table.key(key), value)
+}
+
+// SetPersistent marks the entry at the key in this table as persistent, or clears the flag
+func (table *Table) SetPersistent(key string, persistent bool) error {
+	return table.client.SetPersistent(table.key(key), persistent)
+}
+
+// Delete removes the entry at the key in this table
+func (table *Table) Delete(key string) error {
+	return table.client.Delete(table.key(key))
+}
+
+// AddKeyListener calls the listener with the chosen events for the entry at
+// the key in this table. Notifications carry the key relative to the table.
+func (table *Table) AddKeyListener(key string, events EntryEvent, listener EntryListener) *Listener {
+	return table.client.AddKeyListener(table.key(key), events, table.relativeListener(listener))
+}
+
+// AddTableListener calls the listener with the chosen events for the entries
+// directly in this table. Notifications carry the key relative to the table.
+func (table *Table) AddTableListener(events EntryEvent, listener EntryListener) *Listener {
+	return table.client.AddPrefixListener(table.path+pathSeparator, events, func(notification EntryNotification) {
+		if key, ok := table.relative(notification.Key); ok && !strings.Contains(key, pathSeparator) {
+			notification.Key = key
+			listener(notification)
+		}
+	})
+}
+
+// AddSubTableListener calls the listener with the chosen events for every
+// entry below this table, including those in sub tables. Notifications carry
+// the key relative to the table.
+func (table *Table) AddSubTableListener(events EntryEvent, listener EntryListener) *Listener {
+	return table.client.AddPrefixListener(table.path+pathSeparator, events, table.relativeListener(listener))
+}
+
+func (table *Table) relativeListener(listener EntryListener) EntryListener {
+	return func(notification EntryNotification) {
+		notification.Key, _ = table.relative(notification.Key)
+		listener(notification)
+	}
+}
@@ -0,0 +1,163 @@
+package frcntgo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+	"github.com/techplexengineer/frc-networktables-go/util"
+)
+
+// TopicValue is the set of Go types entries hold as their value. Remote
+// procedure definitions can not be used with a Topic.
+type TopicValue interface {
+	bool | float64 | string | []byte | []bool | []float64 | []string
+}
+
+// Topic is a handle on the entry at a single key, holding values of type T.
+// The type of the entry is fixed by T, so an entry of any other type is
+// reported as a TypeMismatchError and its changes are ignored.
+type Topic[T TopicValue] struct {
+	client    *Client
+	key       string
+	entryType entry.EntryType
+}
+
+// NewTopic returns a handle on the entry at the specified key
+func NewTopic[T TopicValue](client *Client, key string) *Topic[T] {
+	return &Topic[T]{
+		client:    client,
+		key:       util.SanitizeKey(key),
+		entryType: topicType[T](),
+	}
+}
+
+// NewTableTopic returns a handle on the entry at the key in the table
+func NewTableTopic[T TopicValue](table *Table, key string) *Topic[T] {
+	return NewTopic[T](table.client, table.key(key))
+}
+
+// topicType returns the type of entry holding values of type T
+func topicType[T TopicValue]() entry.EntryType {
+	var zero T
+	switch interface{}(zero).(type) {
+	case bool:
+		return entry.TypeBoolean
+	case float64:
+		return entry.TypeDouble
+	case string:
+		return entry.TypeString
+	case []byte:
+		return entry.TypeRaw
+	case []bool:
+		return entry.TypeBooleanArr
+	case []float64:
+		return entry.TypeDoubleArr
+	default:
+		return entry.TypeStringArr
+	}
+}
+
+// copyTopicValue returns a copy of a slice value, since the values of
+// entries are shared with everything reading them
+func copyTopicValue[T TopicValue](value T) T {
+	switch typed := interface{}(value).(type) {
+	case []byte:
+		return interface{}(append([]byte{}, typed...)).(T)
+	case []bool:
+		return interface{}(append([]bool{}, typed...)).(T)
+	case []float64:
+		return interface{}(append([]float64{}, typed...)).(T)
+	case []string:
+		return interface{}(append([]string{}, typed...)).(T)
+	default:
+		return value
+	}
+}
+
+// GetKey returns the key of the entry
+func (topic *Topic[T]) GetKey() string {
+	return topic.key
+}
+
+// GetType returns the type of the entry
+func (topic *Topic[T]) GetType() entry.EntryType {
+	return topic.entryType
+}
+
+// Get fetches the value of the entry
+func (topic *Topic[T]) Get() (T, error) {
+	value, err := topic.client.getValue(topic.key, topic.entryType)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return copyTopicValue(value.(T)), nil
+}
+
+// GetOrDefault fetches the value of the entry, or returns the default value
+// if the entry does not exist or has a different type
+func (topic *Topic[T]) GetOrDefault(defaultValue T) T {
+	if value, err := topic.Get(); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// Set updates the value of the entry, creating it if needed
+func (topic *Topic[T]) Set(value T) error {
+	return topic.client.setValue(topic.key, topic.entryType, copyTopicValue(value))
+}
+
+// Delete removes the entry locally and from the server
+func (topic *Topic[T]) Delete() error {
+	return topic.client.Delete(topic.key)
+}
+
+// Subscribe calls the listener with the new value of the entry and the event
+// that changed it, for the chosen events. The value is the last one the entry
+// had when it was deleted.
+func (topic *Topic[T]) Subscribe(events EntryEvent, listener func(value T, event EntryEvent)) *Listener {
+	return topic.client.AddKeyListener(topic.key, events, func(notification EntryNotification) {
+		if notification.Type != topic.entryType {
+			return
+		}
+		listener(copyTopicValue(notification.Value.(T)), notification.Event)
+	})
+}
+
+// Changes returns a channel receiving the value of the entry whenever it is
+// created or updated, starting with the current value if there is one. Only
+// the latest value is kept for a slow reader. The channel is closed once the
+// context ends.
+func (topic *Topic[T]) Changes(ctx context.Context) <-chan T {
+	changes := make(chan T, 1)
+	var mutex sync.Mutex
+	closed := false
+	listener := topic.Subscribe(EventCreated|EventUpdated|EventLocal|EventImmediate, func(value T, event EntryEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case changes <- value:
+		default:
+			// replace the value the reader has not picked up yet
+			select {
+			case <-changes:
+			default:
+			}
+			changes <- value
+		}
+	})
+	go func() {
+		<-ctx.Done()
+		listener.Unregister()
+		mutex.Lock()
+		defer mutex.Unlock()
+		closed = true
+		close(changes)
+	}()
+	return changes
+}
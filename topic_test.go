@@ -0,0 +1,168 @@
+package frcntgo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/techplexengineer/frc-networktables-go/entry"
+)
+
+func TestTopicGetAndSet(t *testing.T) {
+	server, port := startServer(t)
+	client := startClient(t, port)
+
+	names := NewTableTopic[[]string](client.GetTable("/drive"), "names")
+	if names.GetKey() != "/drive/names" || names.GetType() != entry.TypeStringArr {
+		t.Fatalf("topic is a %s at %s", names.GetType(), names.GetKey())
+	}
+	var notFound *KeyNotFoundError
+	if _, err := names.Get(); !errors.As(err, &notFound) {
+		t.Fatalf("Get of a missing entry returned %v", err)
+	}
+	value := []string{"left", "right"}
+	if err := names.Set(value); err != nil {
+		t.Fatal(err)
+	}
+	// the topic neither keeps nor hands out the entry's own slice
+	value[0] = "changed"
+	got, err := names.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"left", "right"}) {
+		t.Fatalf("Get returned %v", got)
+	}
+	got[1] = "changed"
+	if again, _ := names.Get(); again[1] != "right" {
+		t.Fatal("Get shares its slice with the entry")
+	}
+
+	// an entry of another type is reported, and never read as T
+	server.SetValue("/mode", entry.TypeString, "tank")
+	mode := NewTopic[float64](client, "mode")
+	eventually(t, func() bool { return client.ContainsKey("/mode") }, "the entry never reached the client")
+	var mismatch *TypeMismatchError
+	if _, err := mode.Get(); !errors.As(err, &mismatch) {
+		t.Fatalf("Get of a string entry as a double returned %v", err)
+	}
+	if mismatch.Key != "/mode" || mismatch.Expected != entry.TypeDouble || mismatch.Actual != entry.TypeString {
+		t.Fatalf("got %+v", mismatch)
+	}
+	if value := mode.GetOrDefault(2.5); value != 2.5 {
+		t.Fatalf("GetOrDefault returned %v, want the default", value)
+	}
+	if err := mode.Set(1); err == nil {
+		t.Fatal("Set changed the type of the entry")
+	}
+
+	if err := names.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := names.Get(); !errors.As(err, &notFound) {
+		t.Fatalf("Get of a deleted entry returned %v", err)
+	}
+}
+
+func TestTopicSubscribeIgnoresOtherTypes(t *testing.T) {
+	server, port := startServer(t)
+	client := startClient(t, port)
+
+	type change struct {
+		value float64
+		event EntryEvent
+	}
+	var mutex sync.Mutex
+	var changes []change
+	listener := NewTopic[float64](client, "/speed").Subscribe(EventAll, func(value float64, event EntryEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		changes = append(changes, change{value, event})
+	})
+	defer listener.Unregister()
+
+	// the entry is a string while it first exists
+	server.SetValue("/speed", entry.TypeString, "fast")
+	eventually(t, func() bool { return client.ContainsKey("/speed") }, "the string entry never reached the client")
+	server.Delete("/speed")
+	eventually(t, func() bool { return !client.ContainsKey("/speed") }, "the string entry was never deleted")
+	server.SetValue("/speed", entry.TypeDouble, 1.5)
+	server.SetValue("/speed", entry.TypeDouble, 2.5)
+
+	want := []change{{1.5, EventCreated}, {2.5, EventUpdated}}
+	eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(changes) >= len(want)
+	}, "the listener never got the double entry")
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("listener got %v, want %v", changes, want)
+	}
+}
+
+func TestTopicChanges(t *testing.T) {
+	_, port := startServer(t)
+	client := startClient(t, port)
+	speed := NewTopic[float64](client, "/speed")
+	if err := speed.Set(1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := speed.Changes(ctx)
+	// the current value comes first
+	select {
+	case value := <-changes:
+		if value != 1 {
+			t.Fatalf("received %v, want the current value 1", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the current value was never received")
+	}
+
+	// a slow reader only gets the latest value. Listeners are called in
+	// order, so the one registered last has seen every change once it is
+	// called with the last one.
+	delivered := make(chan struct{})
+	listener := speed.Subscribe(EventUpdated|EventLocal, func(value float64, _ EntryEvent) {
+		if value == 5 {
+			close(delivered)
+		}
+	})
+	defer listener.Unregister()
+	for value := 2.0; value <= 5; value++ {
+		if err := speed.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-delivered
+	if value := <-changes; value != 5 {
+		t.Fatalf("received %v, want the latest value 5", value)
+	}
+	select {
+	case value := <-changes:
+		t.Fatalf("received the replaced value %v", value)
+	default:
+	}
+
+	cancel()
+	select {
+	case value, ok := <-changes:
+		if ok {
+			t.Fatalf("received %v after the context ended", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the channel was never closed")
+	}
+	// changes after the context ended are dropped rather than sent on the
+	// closed channel
+	if err := speed.Set(6); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,21 +1,29 @@
 package util
 
 import (
-	"bytes"
 	"encoding/binary"
+	"errors"
 	"io"
 	"math"
 )
 
-const tableSeperator rune = '/'
+const (
+	tableSeperator rune = '/'
 
-// SanitizeKey ensures that the key does not have any trailing '/'s and starts with a '/'
+	// maxULeb128Length is the number of bytes an unsigned LEB128 value fitting in 32 bits takes up at most
+	maxULeb128Length = 5
+)
+
+var errULeb128Overflow = errors.New("util: LEB128 value does not fit in 32 bits")
+
+// SanitizeKey ensures that the key does not have any trailing '/'s and starts with a '/'.
+// The empty key becomes the root key "/".
 func SanitizeKey(key string) string {
 	sanitized := []rune(key)
-	if sanitized[0] != tableSeperator {
+	if len(sanitized) == 0 || sanitized[0] != tableSeperator {
 		sanitized = append([]rune{tableSeperator}, sanitized...)
 	}
-	if sanitized[len(sanitized)-1] == tableSeperator {
+	if len(sanitized) > 1 && sanitized[len(sanitized)-1] == tableSeperator {
 		sanitized = sanitized[:len(sanitized)-1]
 	}
 	return string(sanitized)
@@ -23,56 +31,92 @@ func SanitizeKey(key string) string {
 
 // EncodeULeb128 encode's an unsigned int32 value to an unsigned LEB128 value. Returns the result in a byte slice
 func EncodeULeb128(value uint32) []byte {
-	remaining := value >> 7
-	var buf = new(bytes.Buffer)
-	for remaining != 0 {
-		buf.WriteByte(byte(value&0x7f | 0x80))
-		value = remaining
-		remaining >>= 7
+	return AppendULeb128(nil, value)
+}
+
+// AppendULeb128 appends the unsigned LEB128 encoding of an unsigned int32 value to a byte slice, and returns the extended slice
+func AppendULeb128(output []byte, value uint32) []byte {
+	for value >= 0x80 {
+		output = append(output, byte(value&0x7f|0x80))
+		value >>= 7
 	}
-	buf.WriteByte(byte(value & 0x7f))
-	return buf.Bytes()
+	return append(output, byte(value))
 }
 
 // ReadULeb128 reads and decodes an unsigned LEB128 value from a ByteReader to an unsigned int32 value. Returns the result as a uint32
-func ReadULeb128(reader io.Reader) (uint32, uint32) {
+// along with the number of bytes read. Readers that are also an io.ByteReader, such as a bufio.Reader, are read without allocating.
+// An error is returned if the reader fails, or if the value does not fit in 32 bits.
+func ReadULeb128(reader io.Reader) (uint32, uint32, error) {
 	var result uint32
 	var ctr uint32
-	var cur = [1]byte{0x80}
-	var err error
-	for (cur[0]&0x80 == 0x80) && ctr < 5 {
-		_, err = io.ReadFull(reader, cur[:])
-		if err != nil {
-			panic(err)
-		}
-		result += uint32((cur[0] & 0x7f)) << (ctr * 7)
+	err := readULeb128(reader, func(cur byte) {
+		result |= uint32(cur&0x7f) << (ctr * 7)
 		ctr++
-	}
-	return result, ctr
+	})
+	return result, ctr, err
 }
 
 // PeekULeb128 reads and decodes an unsigned LEB128 value from a ByteReader to an unsigned int32 value. Returns the result as a uint32,
 // along with the data read. This is more resource intensive than ReadULeb128, and it is advised that whenever possible you should use
 // ReadULeb128 instead.
-func PeekULeb128(reader io.Reader) (uint32, []byte) {
+func PeekULeb128(reader io.Reader) (uint32, []byte, error) {
 	var peeked []byte
 	var result uint32
-	var ctr uint32
-	var cur = [1]byte{0x80}
-	var err error
-	for (cur[0]&0x80 == 0x80) && ctr < 5 {
-		_, err = io.ReadFull(reader, cur[:])
+	err := readULeb128(reader, func(cur byte) {
+		result |= uint32(cur&0x7f) << (len(peeked) * 7)
+		peeked = append(peeked, cur)
+	})
+	return result, peeked, err
+}
+
+// readULeb128 reads the bytes of an unsigned LEB128 value, passing each one to
+// the consume function
+func readULeb128(reader io.Reader, consume func(cur byte)) error {
+	byteReader, ok := reader.(io.ByteReader)
+	if !ok {
+		byteReader = &singleByteReader{reader: reader}
+	}
+	for ctr := 0; ctr < maxULeb128Length; ctr++ {
+		cur, err := byteReader.ReadByte()
+		if err == io.EOF && ctr > 0 {
+			err = io.ErrUnexpectedEOF
+		}
 		if err != nil {
-			panic(err)
+			return err
+		}
+		if ctr == maxULeb128Length-1 && cur > 0x0f {
+			return errULeb128Overflow
+		}
+		consume(cur)
+		if cur&0x80 == 0 {
+			return nil
 		}
-		peeked = append(peeked, cur[0])
-		result += uint32((cur[0] & 0x7f)) << (ctr * 7)
-		ctr++
 	}
-	return result, peeked
+	return errULeb128Overflow
+}
+
+// singleByteReader reads a byte at a time from a reader that is not an io.ByteReader
+type singleByteReader struct {
+	reader io.Reader
+	cur    [1]byte
+}
+
+func (reader *singleByteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(reader.reader, reader.cur[:])
+	return reader.cur[0], err
+}
+
+// SequenceGreater reports whether sequence number a is strictly greater than b
+// using the serial number arithmetic of RFC 1982. Comparisons that RFC 1982
+// leaves undefined (the two numbers are exactly half the range apart) report false.
+func SequenceGreater(a, b uint16) bool {
+	return (a < b && b-a > 1<<15) || (a > b && a-b < 1<<15)
 }
 
 // BytesToFloat64 converts bytes to Float64
+//
+// Deprecated: the bytes are little endian, while NetworkTables sends doubles
+// big endian. Use codec.ReadDouble.
 func BytesToFloat64(bytes []byte) float64 {
 	bits := binary.LittleEndian.Uint64(bytes)
 	float := math.Float64frombits(bits)
@@ -0,0 +1,50 @@
+package util
+
+import "testing"
+
+func TestSanitizeKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"speed", "/speed"},
+		{"/speed", "/speed"},
+		{"/drive/speed/", "/drive/speed"},
+		{"drive/", "/drive"},
+	}
+	for _, test := range tests {
+		if got := SanitizeKey(test.key); got != test.want {
+			t.Errorf("SanitizeKey(%q) = %q, want %q", test.key, got, test.want)
+		}
+	}
+}
+
+func TestSequenceGreater(t *testing.T) {
+	tests := []struct {
+		a, b uint16
+		want bool
+	}{
+		{1, 0, true},
+		{0, 1, false},
+		{0, 0xFFFF, true},
+		{0xFFFF, 0, false},
+		{0x0010, 0xFFF0, true},
+		{0xFFF0, 0x0010, false},
+		{0, 0, false},
+		{0xFFFF, 0xFFFF, false},
+		{0x7FFF, 0, true},
+		{0, 0x7FFF, false},
+		// exactly half the range apart is undefined, so neither is greater
+		{0x8000, 0, false},
+		{0, 0x8000, false},
+		{0xFFFF, 0x7FFF, false},
+		{0x7FFF, 0xFFFF, false},
+	}
+	for _, test := range tests {
+		if got := SequenceGreater(test.a, test.b); got != test.want {
+			t.Errorf("SequenceGreater(%#04x, %#04x) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}